@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/random-dwi/helm-doc/generator"
+	"github.com/random-dwi/helm-doc/helm"
+	"github.com/random-dwi/helm-doc/writer"
+	"github.com/spf13/cobra"
+	"helm.sh/helm/v3/pkg/chart"
+	"io/ioutil"
+	"os"
+)
+
+var changelogFrom string
+var changelogTo string
+var changelogOutputFile string
+
+var changelogCmd = &cobra.Command{
+	Use:   "changelog [flags] CHART",
+	Short: "generate an upgrade guide from the documented values of the versions of a helm chart",
+	Long: "compares each version of a helm chart in its repository with the previous one.\n" +
+		"writes a section per version with new, deprecated and removed keys, changed defaults and the examples of new keys.\n" +
+		"the chart is given as repo/chart or together with --repo.",
+	RunE: runChangelog,
+}
+
+func init() {
+	rootCmd.AddCommand(changelogCmd)
+
+	f := changelogCmd.Flags()
+	f.StringVar(&changelogFrom, "from", "", "oldest version to include (default the oldest version of the repository)")
+	f.StringVar(&changelogTo, "to", "", "newest version to include (default the newest version of the repository)")
+	f.StringVar(&changelogOutputFile, "output-file", "", "write the upgrade guide to FILE instead of printing it")
+}
+
+func runChangelog(cmd *cobra.Command, args []string) error {
+	if len(args) < 1 {
+		return errors.New("chart is required")
+	}
+	if err := writer.WriteUpgradeGuide(ioutil.Discard, "", nil, flags.Output); err != nil {
+		return err
+	}
+
+	// from here on errors are not caused by wrong usage
+	cmd.SilenceUsage = true
+
+	versions, err := helm.ChartVersions(flags.RepoURL, flags.Username, flags.Password, args[0], changelogFrom, changelogTo, flags.Devel,
+		flags.CertFile, flags.KeyFile, flags.CaFile)
+	if err != nil {
+		return err
+	}
+	if len(versions) < 2 {
+		return fmt.Errorf("at least two versions of %s are needed for an upgrade guide, found %d", args[0], len(versions))
+	}
+
+	var charts []*chart.Chart
+	for _, version := range versions {
+		c, err := loadChartVersion(args[0], version)
+		if err != nil {
+			return err
+		}
+		charts = append(charts, c)
+	}
+
+	diffs, err := generator.DiffVersions(charts, flags)
+	if err != nil {
+		return err
+	}
+
+	var rendered bytes.Buffer
+	if err := writer.WriteUpgradeGuide(&rendered, charts[0].Metadata.Name, diffs, flags.Output); err != nil {
+		return err
+	}
+
+	if changelogOutputFile != "" {
+		return ioutil.WriteFile(changelogOutputFile, rendered.Bytes(), 0644)
+	}
+	_, err = os.Stdout.Write(rendered.Bytes())
+	return err
+}
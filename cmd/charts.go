@@ -0,0 +1,286 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/random-dwi/helm-doc/generator"
+	"github.com/random-dwi/helm-doc/helm"
+	"github.com/random-dwi/helm-doc/output"
+	"github.com/random-dwi/helm-doc/writer"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/repo"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// chartResult is the outcome of documenting one chart of several
+type chartResult struct {
+	source string
+	// name and version are taken from the source until the chart is loaded, so charts failing to load are ordered alike
+	name    string
+	version string
+	chart   *chart.Chart
+	docs    []byte
+	err     error
+}
+
+// findChartSources returns the charts of a directory of charts or of a repository index.yaml.
+// It returns nil if name is a single chart.
+func findChartSources(name string) ([]string, error) {
+
+	fi, err := os.Stat(name)
+	if err != nil {
+		return nil, nil
+	}
+
+	if !fi.IsDir() {
+		if filepath.Base(name) == "index.yaml" {
+			return indexChartSources(name)
+		}
+		return nil, nil
+	}
+
+	if _, err := os.Stat(filepath.Join(name, chartutil.ChartfileName)); err == nil {
+		return nil, nil
+	}
+
+	var sources []string
+
+	err = filepath.Walk(name, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			if _, err := os.Stat(filepath.Join(path, chartutil.ChartfileName)); err == nil {
+				sources = append(sources, path)
+				// dependencies of the chart are documented with the chart
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(path, ".tgz") {
+			sources = append(sources, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no charts found in %s", name)
+	}
+
+	return sources, nil
+}
+
+// indexChartSources returns the latest version of every chart of a repository index.
+// Relative urls are resolved against the directory of the index, like helm does for local repositories.
+func indexChartSources(indexFile string) ([]string, error) {
+
+	index, err := repo.LoadIndexFile(indexFile)
+	if err != nil {
+		return nil, err
+	}
+
+	version := ""
+	if flags.Devel {
+		version = ">0.0.0-0"
+	}
+
+	var sources []string
+
+	for name := range index.Entries {
+		chartVersion, err := index.Get(name, version)
+		if err != nil {
+			return nil, fmt.Errorf("unable to find %s in %s: %v", name, indexFile, err)
+		}
+		if len(chartVersion.URLs) == 0 {
+			return nil, fmt.Errorf("%s:%s in %s has no url", name, chartVersion.Version, indexFile)
+		}
+		source := chartVersion.URLs[0]
+		if parsed, err := url.Parse(source); err != nil || !parsed.IsAbs() {
+			source = filepath.Join(filepath.Dir(indexFile), filepath.FromSlash(source))
+		}
+		sources = append(sources, source)
+	}
+
+	return sources, nil
+}
+
+// runCharts documents several charts concurrently and writes them ordered by name and version, followed by a landing page.
+// Charts failing are reported after all charts are written.
+func runCharts(sources []string) error {
+
+	results := make([]*chartResult, len(sources))
+	semaphore := make(chan struct{}, runtime.NumCPU())
+	var wg sync.WaitGroup
+
+	for i, source := range sources {
+		wg.Add(1)
+		go func(i int, source string) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			results[i] = documentChart(source)
+		}(i, source)
+	}
+	wg.Wait()
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return resultLess(results[i], results[j])
+	})
+
+	var failed generator.Errors
+	var documented []*chartResult
+
+	for _, result := range results {
+		if result.err != nil {
+			failed = append(failed, fmt.Errorf("%s: %v", result.source, result.err))
+		} else {
+			documented = append(documented, result)
+		}
+	}
+
+	var rendered bytes.Buffer
+	if err := writeLandingPage(&rendered, documented); err != nil {
+		return err
+	}
+
+	if flags.OutputDir != "" {
+		if err := os.MkdirAll(flags.OutputDir, 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filepath.Join(flags.OutputDir, indexPage), rendered.Bytes(), 0644); err != nil {
+			return err
+		}
+	} else {
+		for _, result := range documented {
+			rendered.Write(result.docs)
+		}
+		var err error
+		if flags.OutputFile != "" {
+			err = ioutil.WriteFile(flags.OutputFile, rendered.Bytes(), 0644)
+		} else {
+			_, err = os.Stdout.Write(rendered.Bytes())
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("docs of %d of %d charts failed:\n%v", len(failed), len(results), failed)
+	}
+
+	return nil
+}
+
+// documentChart renders the docs of a chart, or writes them to a directory named after the chart and its version if --output-dir is set
+func documentChart(source string) *chartResult {
+
+	result := &chartResult{source: source}
+	result.name, result.version = sourceNameVersion(source)
+
+	chartPath := source
+	if parsed, err := url.Parse(source); err == nil && parsed.IsAbs() {
+		chartPath, result.err = helm.LocateChartPath("", flags.Username, flags.Password, source, "", flags.Verify, flags.Keyring,
+			flags.CertFile, flags.KeyFile, flags.CaFile)
+		if result.err != nil {
+			return result
+		}
+	}
+
+	result.chart, result.err = loader.Load(chartPath)
+	if result.err != nil {
+		return result
+	}
+
+	result.name, result.version = result.chart.Metadata.Name, result.chart.Metadata.Version
+
+	output.Debugf("documenting %s:%s from %s", result.name, result.version, source)
+
+	if flags.OutputDir != "" {
+		result.err = writeChartDocsDir(result.chart, filepath.Join(flags.OutputDir, chartDirName(result.chart.Metadata)))
+		return result
+	}
+
+	// the landing page links to the anchor, the headings of several versions of a chart are the same
+	var rendered bytes.Buffer
+	fmt.Fprintf(&rendered, "<a id=\"%s\"></a>\n\n", chartDirName(result.chart.Metadata))
+	result.err = renderChartDocs(result.chart, &rendered)
+	result.docs = rendered.Bytes()
+
+	return result
+}
+
+// writeLandingPage lists the documented charts, linked to their docs
+func writeLandingPage(out io.Writer, results []*chartResult) error {
+
+	var metaData []*chart.Metadata
+	var links []string
+
+	for _, result := range results {
+		metaData = append(metaData, result.chart.Metadata)
+		if flags.OutputDir != "" {
+			links = append(links, chartDirName(result.chart.Metadata)+"/"+indexPage)
+		} else {
+			links = append(links, "#"+chartDirName(result.chart.Metadata))
+		}
+	}
+
+	return writer.NewMarkdownWriter(out).WriteChartTable("Charts", metaData, links)
+}
+
+// chartDirName is the directory of the docs of a chart, several versions of a chart are documented side by side
+func chartDirName(metaData *chart.Metadata) string {
+	return metaData.Name + "-" + metaData.Version
+}
+
+// sourceNameVersion returns the name and version of a chart from its source, e.g. `app` and `1.0.0` for `charts/app-1.0.0.tgz`.
+// The version of chart directories is unknown.
+func sourceNameVersion(source string) (string, string) {
+
+	base := path.Base(filepath.ToSlash(source))
+	if !strings.HasSuffix(base, ".tgz") {
+		return base, ""
+	}
+	base = strings.TrimSuffix(base, ".tgz")
+
+	// names may contain dashes as well, the version is the first suffix parsing as semantic version
+	for i, char := range base {
+		if char == '-' {
+			if _, err := semver.StrictNewVersion(base[i+1:]); err == nil {
+				return base[:i], base[i+1:]
+			}
+		}
+	}
+
+	return base, ""
+}
+
+// resultLess orders charts by name and semantic version, no matter if they failed to load
+func resultLess(a *chartResult, b *chartResult) bool {
+
+	if a.name != b.name {
+		return a.name < b.name
+	}
+
+	aVersion, aErr := semver.NewVersion(a.version)
+	bVersion, bErr := semver.NewVersion(b.version)
+	if aErr != nil || bErr != nil {
+		return a.version < b.version
+	}
+
+	return aVersion.LessThan(bVersion)
+}
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// writeTestChart writes a documented chart to dir
+func writeTestChart(t *testing.T, dir string, name string, version string) {
+	files := map[string]string{
+		"Chart.yaml":       fmt.Sprintf("apiVersion: v2\nname: %s\nversion: %s\n", name, version),
+		"values.yaml":      "replicas: 1\n",
+		"definitions.yaml": "replicas: number of replicas\n",
+		"examples.yaml":    "replicas: 2\n",
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for file, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, file), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func Test_findChartSources(t *testing.T) {
+	dir := t.TempDir()
+	writeTestChart(t, filepath.Join(dir, "charts", "app"), "app", "1.0.0")
+	writeTestChart(t, filepath.Join(dir, "charts", "nested", "db"), "db", "1.0.0")
+	if err := ioutil.WriteFile(filepath.Join(dir, "charts", "cache-1.0.0.tgz"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "empty"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	index := "apiVersion: v1\nentries:\n" +
+		"  app:\n  - name: app\n    version: 1.9.0\n    urls: [app-1.9.0.tgz]\n  - name: app\n    version: 1.10.0\n    urls: [app-1.10.0.tgz]\n" +
+		"  db:\n  - name: db\n    version: 2.0.0\n    urls: [https://charts.example.com/db-2.0.0.tgz]\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "index.yaml"), []byte(index), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "directory_of_charts", path: filepath.Join(dir, "charts"),
+			want: []string{filepath.Join(dir, "charts", "app"), filepath.Join(dir, "charts", "cache-1.0.0.tgz"), filepath.Join(dir, "charts", "nested", "db")}},
+		{name: "single_chart", path: filepath.Join(dir, "charts", "app"), want: nil},
+		{name: "index", path: filepath.Join(dir, "index.yaml"),
+			want: []string{filepath.Join(dir, "app-1.10.0.tgz"), "https://charts.example.com/db-2.0.0.tgz"}},
+		{name: "not_a_file", path: "stable/app", want: nil},
+		{name: "no_charts", path: filepath.Join(dir, "empty"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := findChartSources(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("findChartSources() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findChartSources() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_runCharts(t *testing.T) {
+	dir := t.TempDir()
+	var sources []string
+	for _, version := range []string{"1.10.0", "1.9.0"} {
+		source := filepath.Join(dir, "charts", "app-"+version)
+		writeTestChart(t, source, "app", version)
+		sources = append(sources, source)
+	}
+	writeTestChart(t, filepath.Join(dir, "charts", "db"), "db", "1.0.0")
+	sources = append(sources, filepath.Join(dir, "charts", "db"))
+
+	outputDir := filepath.Join(dir, "docs")
+	defer func(outputDir string) { flags.OutputDir = outputDir }(flags.OutputDir)
+	flags.OutputDir = outputDir
+
+	if err := runCharts(sources); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, page := range []string{"app-1.9.0/index.md", "app-1.10.0/index.md", "db-1.0.0/index.md"} {
+		if _, err := os.Stat(filepath.Join(outputDir, page)); err != nil {
+			t.Errorf("runCharts() did not write %s: %v", page, err)
+		}
+	}
+
+	landingPage, err := ioutil.ReadFile(filepath.Join(outputDir, indexPage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var links []string
+	for _, line := range strings.Split(string(landingPage), "\n") {
+		if start := strings.Index(line, "]("); start >= 0 {
+			links = append(links, line[start+2:start+strings.Index(line[start:], ")")])
+		}
+	}
+	want := []string{"app-1.9.0/index.md", "app-1.10.0/index.md", "db-1.0.0/index.md"}
+	if !reflect.DeepEqual(links, want) {
+		t.Errorf("runCharts() landing page links = %v, want %v\n%s", links, want, landingPage)
+	}
+}
+
+func Test_sourceNameVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      string
+		wantName    string
+		wantVersion string
+	}{
+		{name: "directory", source: "charts/app", wantName: "app"},
+		{name: "archive", source: "charts/app-1.10.0.tgz", wantName: "app", wantVersion: "1.10.0"},
+		{name: "dashed_name", source: "charts/my-app-1.0.0-rc.1.tgz", wantName: "my-app", wantVersion: "1.0.0-rc.1"},
+		{name: "url", source: "https://charts.example.com/db-2.0.0.tgz", wantName: "db", wantVersion: "2.0.0"},
+		{name: "no_version", source: "charts/app.tgz", wantName: "app"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotName, gotVersion := sourceNameVersion(tt.source)
+			if gotName != tt.wantName || gotVersion != tt.wantVersion {
+				t.Errorf("sourceNameVersion() = %s, %s, want %s, %s", gotName, gotVersion, tt.wantName, tt.wantVersion)
+			}
+		})
+	}
+}
+
+func Test_resultLess(t *testing.T) {
+	results := []*chartResult{
+		{source: "db-1.0.0", name: "db", version: "1.0.0"},
+		{source: "app-1.10.0", name: "app", version: "1.10.0"},
+		{source: "broken-app-1.9.5", name: "app", version: "1.9.5", err: fmt.Errorf("unable to load")},
+		{source: "app-1.9.0", name: "app", version: "1.9.0"},
+		{source: "broken-cache", name: "cache", err: fmt.Errorf("unable to load")},
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return resultLess(results[i], results[j])
+	})
+
+	var got []string
+	for _, result := range results {
+		got = append(got, result.source)
+	}
+	want := []string{"app-1.9.0", "broken-app-1.9.5", "app-1.10.0", "broken-cache", "db-1.0.0"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("resultLess() order = %v, want %v", got, want)
+	}
+}
+
+func Test_runCharts_singleFile(t *testing.T) {
+	dir := t.TempDir()
+	var sources []string
+	for _, version := range []string{"1.10.0", "1.9.0"} {
+		source := filepath.Join(dir, "charts", "app-"+version)
+		writeTestChart(t, source, "app", version)
+		sources = append(sources, source)
+	}
+
+	outputFile := filepath.Join(dir, "README.md")
+	defer func(outputFile string) { flags.OutputFile = outputFile }(flags.OutputFile)
+	flags.OutputFile = outputFile
+
+	if err := runCharts(sources); err != nil {
+		t.Fatal(err)
+	}
+
+	rendered, err := ioutil.ReadFile(outputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, anchor := range []string{"app-1.9.0", "app-1.10.0"} {
+		if !strings.Contains(string(rendered), "](#"+anchor+")") {
+			t.Errorf("runCharts() does not link %s\n%s", anchor, rendered)
+		}
+		if !strings.Contains(string(rendered), `<a id="`+anchor+`"></a>`) {
+			t.Errorf("runCharts() has no anchor %s\n%s", anchor, rendered)
+		}
+	}
+	if strings.Index(string(rendered), `<a id="app-1.9.0">`) > strings.Index(string(rendered), `<a id="app-1.10.0">`) {
+		t.Errorf("runCharts() wrote app-1.10.0 before app-1.9.0\n%s", rendered)
+	}
+}
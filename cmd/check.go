@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/random-dwi/helm-doc/output"
+	"github.com/random-dwi/helm-doc/writer"
+	"github.com/spf13/cobra"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var checkFile string
+
+var checkCmd = &cobra.Command{
+	Use:   "check [flags] CHART",
+	Short: "check that the committed doc of a helm chart is up to date",
+	Long:  "renders the doc of a helm chart and compares it with a committed file.\nif the file contains helm-doc marker comments, only the section between them is compared.\nprints a unified diff and fails if they differ.",
+	RunE:  runCheck,
+}
+
+func init() {
+	rootCmd.AddCommand(checkCmd)
+
+	f := checkCmd.Flags()
+	f.StringVarP(&checkFile, "file", "f", "", "committed doc to compare with (default README.md in the chart directory)")
+}
+
+func runCheck(cmd *cobra.Command, args []string) error {
+	if len(args) < 1 {
+		return errors.New("chart is required")
+	}
+
+	// from here on errors are not caused by wrong usage
+	cmd.SilenceUsage = true
+
+	chartPath, err := locateChart(args[0])
+	if err != nil {
+		return err
+	}
+
+	c, err := loader.Load(chartPath)
+	if err != nil {
+		return err
+	}
+
+	file := checkFile
+	if file == "" {
+		if fi, err := os.Stat(chartPath); err != nil || !fi.IsDir() {
+			return errors.New("--file is required if the chart is not a directory")
+		}
+		file = filepath.Join(chartPath, "README.md")
+	}
+
+	committed, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	var rendered bytes.Buffer
+	if err := renderChartDocs(c, &rendered); err != nil {
+		return err
+	}
+
+	expected := rendered.Bytes()
+	if writer.HasInjectMarkers(committed) {
+		// only the injected section is generated
+		expected, err = writer.Inject(committed, expected)
+		if err != nil {
+			return fmt.Errorf("unable to inject doc into %s: %v", file, err)
+		}
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(committed)),
+		B:        difflib.SplitLines(string(expected)),
+		FromFile: file,
+		ToFile:   "generated",
+		Context:  3,
+	})
+	if err != nil {
+		return err
+	}
+
+	if diff == "" {
+		output.Debugf("doc is up to date: %s", file)
+		return nil
+	}
+
+	output.Infof("%s", strings.TrimSuffix(diff, "\n"))
+
+	return fmt.Errorf("doc is out of date: %s (regenerate it with `helm doc` or `helm doc --inject`)", file)
+}
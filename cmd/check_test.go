@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"bytes"
+	"github.com/spf13/cobra"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_runCheck(t *testing.T) {
+	dir := t.TempDir()
+	chartDir := filepath.Join(dir, "app")
+	writeTestChart(t, chartDir, "app", "1.0.0")
+
+	c, err := loader.Load(chartDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rendered bytes.Buffer
+	if err := renderChartDocs(c, &rendered); err != nil {
+		t.Fatal(err)
+	}
+	docs := strings.TrimRight(rendered.String(), "\n")
+
+	tests := []struct {
+		name    string
+		readme  string
+		wantErr string
+	}{
+		{name: "up_to_date", readme: rendered.String()},
+		{name: "up_to_date_between_markers", readme: "# intro\n<!-- helm-doc:start -->\n\n" + docs + "\n\n<!-- helm-doc:end -->\n"},
+		{name: "stale", readme: "# app\n", wantErr: "doc is out of date"},
+		{name: "stale_between_markers", readme: "# intro\n<!-- helm-doc:start -->\n\nold docs\n\n<!-- helm-doc:end -->\n", wantErr: "doc is out of date"},
+		{name: "missing_start_marker", readme: "# intro\n\n" + docs + "\n\n<!-- helm-doc:end -->\n", wantErr: "start marker not found"},
+		{name: "missing_end_marker", readme: "# intro\n<!-- helm-doc:start -->\n\n" + docs + "\n\n", wantErr: "end marker not found"},
+		{name: "duplicated_markers", readme: "<!-- helm-doc:start -->\n\n" + docs + "\n\n<!-- helm-doc:end -->\n<!-- helm-doc:start -->\n<!-- helm-doc:end -->\n",
+			wantErr: "marker found more than once"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ioutil.WriteFile(filepath.Join(chartDir, "README.md"), []byte(tt.readme), 0644); err != nil {
+				t.Fatal(err)
+			}
+			err := runCheck(&cobra.Command{}, []string{chartDir})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("runCheck() error = %v, want none", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("runCheck() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"github.com/random-dwi/helm-doc/generator"
@@ -8,10 +9,13 @@ import (
 	"github.com/random-dwi/helm-doc/output"
 	"github.com/random-dwi/helm-doc/writer"
 	"github.com/spf13/cobra"
-	"k8s.io/helm/pkg/chartutil"
-	"k8s.io/helm/pkg/proto/hapi/chart"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"io"
+	"io/ioutil"
 	"log"
 	"os"
+	"strings"
 )
 
 var version string
@@ -22,7 +26,15 @@ var rootCmd = &cobra.Command{
 	Use:   "doc [flags] CHART",
 	Short: fmt.Sprintf("generate doc for a helm chart"),
 	Long:  fmt.Sprintf("helm plugin to generate documentation for helm charts.\nversion: %s buildTime: %s gitCommit: %s", version, buildTime, gitCommit),
+	Args:  cobra.ArbitraryArgs,
 	RunE:  run,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if flags.Verbose {
+			output.DebugLogger = log.New(os.Stderr, "[doc] ", log.LstdFlags)
+		}
+	},
+	// errors are printed by main
+	SilenceErrors: true,
 }
 
 var flags generator.CommandFlags
@@ -38,10 +50,14 @@ func defaultKeyring() string {
 
 func init() {
 
-	f := rootCmd.Flags()
+	f := rootCmd.PersistentFlags()
 	f.BoolVarP(&flags.VerifyExamples, "verify-examples", "", true, "verify presence of examples for configs without default value")
+	f.BoolVar(&flags.VerifyExamplesRender, "verify-examples-render", false, "verify the chart renders with the examples merged over the default values")
 	f.BoolVarP(&flags.VerifyValues, "verify-values", "", true, "verify all default values are documented")
 	f.BoolVarP(&flags.VerifyDependencies, "verify-dependencies", "", false, "verify dependencies are documented")
+	f.BoolVarP(&flags.VerifyTemplates, "verify-templates", "", false, "verify all values used in templates are documented or have a default value")
+	f.BoolVarP(&flags.VerifyUsage, "verify-usage", "", false, "verify all documented and default values are used by a template")
+	f.BoolVar(&flags.UsedIn, "used-in", false, "show the templates and kinds of resources using each value")
 	f.StringVar(&flags.Version, "version", "", "Specify the exact chart version to use. If this is not specified, the latest version is used")
 	f.StringVar(&flags.RepoURL, "repo", "", "Chart repository url where to locate the requested chart")
 	f.StringVar(&flags.Username, "username", "", "Chart repository username where to locate the requested chart")
@@ -52,81 +68,219 @@ func init() {
 	f.StringVar(&flags.CaFile, "ca-file", "", "Verify certificates of HTTPS-enabled servers using this CA bundle")
 	f.BoolVar(&flags.Verify, "verify", false, "Verify the package before using it")
 	f.BoolVar(&flags.Devel, "devel", false, "Use development versions, too. Equivalent to version '>0.0.0-0'. If --version is set, this is ignored.")
+	f.BoolVar(&flags.ParseComments, "parse-comments", false, "read descriptions from '# --' and '## @param' comments in values.yaml, definitions.yaml wins on conflicts")
+	f.StringVarP(&flags.Output, "output", "o", writer.DefaultFormat, fmt.Sprintf("output format, one of: %s", strings.Join(writer.Formats(), ", ")))
+
+	rootCmd.Flags().StringVar(&flags.Inject, "inject", "", "inject the doc into FILE between <!-- helm-doc:start --> and <!-- helm-doc:end --> instead of printing it")
+	rootCmd.Flags().StringVar(&flags.OutputFile, "output-file", "", "write the doc to FILE instead of printing it")
+	rootCmd.Flags().StringVar(&flags.VerifyDeprecated, "verify-deprecated", "", "warn about deprecated values set in VALUES_FILE")
+	rootCmd.Flags().StringVar(&flags.MigrateValues, "migrate-values", "", "write VALUES_FILE of --verify-deprecated to FILE with the values of deprecated keys moved to the keys replacing them")
+	rootCmd.Flags().StringVar(&flags.OutputDir, "output-dir", "", "write a markdown page per chart and an index.md with the dependency tree to DIR")
 
-	if os.Getenv("HELM_DEBUG") == "1" {
+	if helm.Settings().Debug {
 		flags.Verbose = true
 	}
 }
 
 func run(cmd *cobra.Command, args []string) error {
 	if len(args) < 1 {
-		return errors.New("c is required")
+		return errors.New("chart is required")
+	}
+	if flags.Inject != "" && flags.OutputFile != "" {
+		return errors.New("--inject and --output-file cannot be combined")
+	}
+	if flags.OutputDir != "" && (flags.Inject != "" || flags.OutputFile != "") {
+		return errors.New("--output-dir cannot be combined with --inject or --output-file")
+	}
+	if flags.MigrateValues != "" && flags.VerifyDeprecated == "" {
+		return errors.New("--migrate-values requires --verify-deprecated")
+	}
+	if flags.OutputDir != "" && flags.Output != writer.DefaultFormat {
+		return fmt.Errorf("--output-dir only supports the %s format", writer.DefaultFormat)
+	}
+	if _, err := writer.NewDocumentationWriter(flags.Output, ioutil.Discard); err != nil {
+		return err
+	}
+
+	// from here on errors are not caused by wrong usage
+	cmd.SilenceUsage = true
+
+	sources, err := findChartSources(args[0])
+	if err != nil {
+		return err
+	}
+	if sources != nil {
+		if flags.Inject != "" || flags.VerifyDeprecated != "" {
+			return errors.New("--inject and --verify-deprecated are not supported for several charts")
+		}
+		if flags.Output != writer.DefaultFormat {
+			return fmt.Errorf("several charts are only supported with the %s format", writer.DefaultFormat)
+		}
+		return runCharts(sources)
+	}
+
+	chartPath, err := locateChart(args[0])
+	if err != nil {
+		return err
+	}
+
+	c, err := loader.Load(chartPath)
+	if err != nil {
+		return err
 	}
 
-	if flags.Verbose {
-		output.DebugLogger = log.New(os.Stderr, "[doc] ", log.LstdFlags)
+	if flags.Inject != "" {
+		return injectChartDocs(c, flags.Inject)
 	}
 
-	output.Debugf("helm home: %s", os.Getenv("HELM_HOME"))
+	if flags.OutputFile != "" {
+		return writeChartDocsFile(c, flags.OutputFile)
+	}
+
+	if flags.OutputDir != "" {
+		return writeChartDocsDir(c, flags.OutputDir)
+	}
 
-	output.Debugf("Original c version: %q", flags.Version)
+	return renderChartDocs(c, os.Stdout)
+}
+
+// locateChart resolves the chart argument to a local path, downloading the chart if needed
+func locateChart(name string) (string, error) {
+
+	output.Debugf("helm repository config: %s", helm.Settings().RepositoryConfig)
+	output.Debugf("helm repository cache: %s", helm.Settings().RepositoryCache)
+
+	output.Debugf("Original chart version: %q", flags.Version)
 	if flags.Version == "" && flags.Devel {
 		output.Debugf("setting version to >0.0.0-0")
 		flags.Version = ">0.0.0-0"
 	}
 
-	cp, err := helm.LocateChartPath(flags.RepoURL, flags.Username, flags.Password, args[0], flags.Version, flags.Verify, flags.Keyring,
+	chartPath, err := helm.LocateChartPath(flags.RepoURL, flags.Username, flags.Password, name, flags.Version, flags.Verify, flags.Keyring,
 		flags.CertFile, flags.KeyFile, flags.CaFile)
 	if err != nil {
-		return err
+		return "", err
 	}
-	var chartPath = cp
 
 	output.Debugf("ChartPath is: %s", chartPath)
 
-	c, err := chartutil.Load(chartPath)
+	return chartPath, nil
+}
+
+// renderChartDocs generates the docs of a chart and its dependencies and writes them to out
+func renderChartDocs(c *chart.Chart, out io.Writer) error {
+
+	gen, err := writer.NewDocumentationWriter(flags.Output, out)
 	if err != nil {
 		return err
 	}
 
-	var gen writer.DocumentationWriter = writer.NewMarkdownWriter(os.Stdout)
+	chartDocs, err := generator.GenerateChartDocs(c, flags)
+	printWarnings(chartDocs)
+	if err != nil {
+		return err
+	}
 
-	parentCharts := make(map[*chart.Chart]*chart.Chart)
-	writeChartDocs(c, 1, gen, parentCharts, nil)
+	if flags.MigrateValues != "" {
+		if err := migrateValuesFile(chartDocs, flags.VerifyDeprecated, flags.MigrateValues); err != nil {
+			return err
+		}
+	}
 
-	return nil
+	if err := writeChartDocs(chartDocs, 1, 0, gen); err != nil {
+		return err
+	}
+	if err := gen.WriteGlobals(chartDocs.Globals, 2); err != nil {
+		return err
+	}
+
+	return gen.Flush()
 }
 
-func writeChartDocs(chart *chart.Chart, layer int, gen writer.DocumentationWriter, parentCharts map[*chart.Chart]*chart.Chart, parent *chart.Chart) {
+// printWarnings prints the problems of a chart and its dependencies which do not fail the docs
+func printWarnings(chartDocs *generator.ChartDocs) {
+	for _, warning := range chartDocs.Warnings {
+		output.Warnf("%v", warning)
+	}
+}
 
-	var dependencyNames []string
+// migrateValuesFile writes a values file with the values of deprecated keys moved to the keys replacing them
+func migrateValuesFile(chartDocs *generator.ChartDocs, valuesFile string, migratedFile string) error {
 
-	for _, dependency := range chart.Dependencies {
-		dependencyNames = append(dependencyNames, dependency.Metadata.Name)
+	rawValues, err := ioutil.ReadFile(valuesFile)
+	if err != nil {
+		return err
 	}
 
-	parentCharts[chart] = parent
+	migrated, moved, err := generator.MigrateValues(chartDocs, rawValues)
+	if err != nil {
+		return fmt.Errorf("unable to migrate %s: %v", valuesFile, err)
+	}
+	for _, key := range moved {
+		output.Debugf("moved %s", key)
+	}
 
-	output.Debugf("generating docs for %s:%s", chart.Metadata.Name, chart.Metadata.Version)
-	docs, err := generator.GenerateDocs(chart, dependencyNames, parentCharts, flags)
+	return ioutil.WriteFile(migratedFile, migrated, 0644)
+}
+
+// writeChartDocsFile writes the docs to a file, which is left untouched if generating the docs fails
+func writeChartDocsFile(c *chart.Chart, file string) error {
+
+	var rendered bytes.Buffer
+	if err := renderChartDocs(c, &rendered); err != nil {
+		return err
+	}
 
+	return ioutil.WriteFile(file, rendered.Bytes(), 0644)
+}
+
+// injectChartDocs replaces the generated section of an existing file, keeping everything around it
+func injectChartDocs(c *chart.Chart, file string) error {
+
+	fi, err := os.Stat(file)
 	if err != nil {
-		if parent == nil || flags.VerifyDependencies {
-			output.Failf("%v", err)
-		} else {
-			output.Warnf("%v", err)
-		}
+		return err
 	}
 
-	gen.WriteMetaData(chart.Metadata, layer)
-	gen.WriteDocs(docs)
+	document, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	var rendered bytes.Buffer
+	if err := renderChartDocs(c, &rendered); err != nil {
+		return err
+	}
+
+	injected, err := writer.Inject(document, rendered.Bytes())
+	if err != nil {
+		return fmt.Errorf("unable to inject doc into %s: %v", file, err)
+	}
+
+	return ioutil.WriteFile(file, injected, fi.Mode())
+}
+
+func writeChartDocs(chartDocs *generator.ChartDocs, layer int, depth int, gen writer.DocumentationWriter) error {
+
+	if err := gen.WriteMetaData(chartDocs.Chart.Metadata, chartDocs.Declaration, layer, depth); err != nil {
+		return err
+	}
+	if err := gen.WriteDocs(chartDocs.Docs); err != nil {
+		return err
+	}
 
-	if len(chart.Dependencies) > 0 {
+	if len(chartDocs.Dependencies) > 0 {
 		layer++
-		gen.WriteChapter("Dependencies", layer)
+		if err := gen.WriteChapter("Dependencies", layer); err != nil {
+			return err
+		}
 		layer++
-		for _, dependency := range chart.Dependencies {
-			writeChartDocs(dependency, layer, gen, parentCharts, chart)
+		for _, dependency := range chartDocs.Dependencies {
+			if err := writeChartDocs(dependency, layer, depth+1, gen); err != nil {
+				return err
+			}
 		}
 	}
+
+	return nil
 }
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/random-dwi/helm-doc/generator"
+	"github.com/random-dwi/helm-doc/helm"
+	"github.com/random-dwi/helm-doc/writer"
+	"github.com/spf13/cobra"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"io/ioutil"
+	"os"
+)
+
+var diffFrom string
+var diffTo string
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [flags] CHART",
+	Short: "show the changes of the documented values between two versions of a helm chart",
+	Long: "compares the documented values of two versions of a helm chart and its dependencies.\n" +
+		"reports added, removed and renamed keys, changed defaults and changed descriptions.\n" +
+		"removed and renamed keys, changed defaults and new required keys without default are marked as breaking.",
+	RunE: runDiff,
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	f := diffCmd.Flags()
+	f.StringVar(&diffFrom, "from", "", "version of the chart to compare from")
+	f.StringVar(&diffTo, "to", "", "version of the chart to compare to")
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	if len(args) < 1 {
+		return errors.New("chart is required")
+	}
+	if diffFrom == "" || diffTo == "" {
+		return errors.New("--from and --to are required")
+	}
+	if err := writer.WriteDiff(ioutil.Discard, &generator.Diff{}, flags.Output); err != nil {
+		return err
+	}
+
+	// from here on errors are not caused by wrong usage
+	cmd.SilenceUsage = true
+
+	from, err := loadChartVersion(args[0], diffFrom)
+	if err != nil {
+		return err
+	}
+	to, err := loadChartVersion(args[0], diffTo)
+	if err != nil {
+		return err
+	}
+
+	diff, err := generator.DiffCharts(from, to, flags)
+	if err != nil {
+		return err
+	}
+
+	return writer.WriteDiff(os.Stdout, diff, flags.Output)
+}
+
+// loadChartVersion fetches a version of a chart from its repository
+func loadChartVersion(name string, version string) (*chart.Chart, error) {
+
+	chartPath, err := helm.LocateChartPath(flags.RepoURL, flags.Username, flags.Password, name, version, flags.Verify, flags.Keyring,
+		flags.CertFile, flags.KeyFile, flags.CaFile)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := loader.Load(chartPath)
+	if err != nil {
+		return nil, err
+	}
+	if c.Metadata.Version != version {
+		return nil, fmt.Errorf("%s is version %s of %s, not %s (hint: a chart directory has a single version, use a repository)", chartPath, c.Metadata.Version, c.Metadata.Name, version)
+	}
+
+	return c, nil
+}
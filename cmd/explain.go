@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"errors"
+	"github.com/random-dwi/helm-doc/generator"
+	"github.com/random-dwi/helm-doc/writer"
+	"github.com/spf13/cobra"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"io/ioutil"
+	"os"
+)
+
+var explainCmd = &cobra.Command{
+	Use:   "explain [flags] CHART KEY",
+	Short: "show the documentation of a single value of a helm chart",
+	Long: "prints the description, default, example and child keys of a value of a helm chart or its dependencies,\n" +
+		"together with the chart in the dependency tree owning it, similar to `kubectl explain`.\n" +
+		"keys are written as seen by the chart, e.g. `db.user.name` or `hosts[].name`.",
+	RunE: runExplain,
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+}
+
+func runExplain(cmd *cobra.Command, args []string) error {
+	if len(args) < 2 {
+		return errors.New("chart and key are required")
+	}
+	if err := writer.WriteExplanation(ioutil.Discard, &generator.Explanation{}, flags.Output); err != nil {
+		return err
+	}
+
+	// from here on errors are not caused by wrong usage
+	cmd.SilenceUsage = true
+
+	chartPath, err := locateChart(args[0])
+	if err != nil {
+		return err
+	}
+
+	c, err := loader.Load(chartPath)
+	if err != nil {
+		return err
+	}
+
+	explanation, err := generator.ExplainKey(c, args[1], flags)
+	if err != nil {
+		return err
+	}
+
+	return writer.WriteExplanation(os.Stdout, explanation, flags.Output)
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"errors"
+	"github.com/random-dwi/helm-doc/generator"
+	"github.com/random-dwi/helm-doc/output"
+	"github.com/spf13/cobra"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var initCmd = &cobra.Command{
+	Use:   "init [flags] CHART",
+	Short: "scaffold definitions.yaml and examples.yaml for a helm chart",
+	Long: "adds a TODO description to definitions.yaml for every undocumented value of a chart directory\n" +
+		"and a TODO example to examples.yaml for every documented key without default value.\n" +
+		"existing definitions and examples are kept.",
+	RunE: runInit,
+}
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+}
+
+func runInit(cmd *cobra.Command, args []string) error {
+	if len(args) < 1 {
+		return errors.New("chart is required")
+	}
+
+	if fi, err := os.Stat(args[0]); err != nil || !fi.IsDir() {
+		return errors.New("chart has to be a directory")
+	}
+
+	// from here on errors are not caused by wrong usage
+	cmd.SilenceUsage = true
+
+	c, err := loader.Load(args[0])
+	if err != nil {
+		return err
+	}
+
+	definitions, addedDefinitions, err := generator.ScaffoldDefinitions(c, flags)
+	if err != nil {
+		return err
+	}
+
+	if err := writeScaffold(filepath.Join(args[0], "definitions.yaml"), definitions, addedDefinitions); err != nil {
+		return err
+	}
+
+	examples, addedExamples, err := generator.ScaffoldExamples(c, definitions, flags)
+	if err != nil {
+		return err
+	}
+
+	return writeScaffold(filepath.Join(args[0], "examples.yaml"), examples, addedExamples)
+}
+
+func writeScaffold(file string, content []byte, addedKeys []string) error {
+
+	if len(addedKeys) == 0 {
+		output.Infof("%s is complete", file)
+		return nil
+	}
+
+	if err := ioutil.WriteFile(file, content, 0644); err != nil {
+		return err
+	}
+
+	var prefix = "\n\t"
+	output.Infof("added %d keys to %s: %s%s", len(addedKeys), file, prefix, strings.Join(addedKeys, prefix))
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/random-dwi/helm-doc/generator"
+	"github.com/random-dwi/helm-doc/writer"
+	"github.com/spf13/cobra"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var lintFormat string
+var lintOutputFile string
+
+var lintCmd = &cobra.Command{
+	Use:   "lint [flags] CHART",
+	Short: "report all problems of a helm chart for CI",
+	Long: "validates a helm chart and its dependencies and reports every finding as JUnit XML, SARIF or checkstyle XML.\n" +
+		"problems of dependencies are warnings unless --verify-dependencies is set.\n" +
+		"fails if any error is found.",
+	RunE: runLint,
+}
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+
+	f := lintCmd.Flags()
+	f.StringVar(&lintFormat, "format", "sarif", fmt.Sprintf("report format, one of: %s", strings.Join(writer.LintReportFormats(), ", ")))
+	f.StringVar(&lintOutputFile, "output-file", "", "write the report to FILE instead of printing it")
+}
+
+func runLint(cmd *cobra.Command, args []string) error {
+	if len(args) < 1 {
+		return errors.New("chart is required")
+	}
+	if err := writer.WriteLintReport(ioutil.Discard, nil, lintFormat); err != nil {
+		return err
+	}
+
+	// from here on errors are not caused by wrong usage
+	cmd.SilenceUsage = true
+
+	chartPath, err := locateChart(args[0])
+	if err != nil {
+		return err
+	}
+
+	c, err := loader.Load(chartPath)
+	if err != nil {
+		return err
+	}
+
+	findings := generator.LintChart(c, flags)
+
+	// files of local charts are reported relative to the working directory, so CI can annotate them
+	if fi, err := os.Stat(args[0]); err == nil && fi.IsDir() {
+		for _, finding := range findings {
+			if finding.File != "" {
+				finding.File = filepath.ToSlash(filepath.Join(args[0], finding.File))
+			}
+			for i, location := range finding.Locations {
+				finding.Locations[i] = filepath.ToSlash(filepath.Join(args[0], location))
+			}
+		}
+	}
+
+	var report bytes.Buffer
+	if err := writer.WriteLintReport(&report, findings, lintFormat); err != nil {
+		return err
+	}
+
+	if lintOutputFile != "" {
+		err = ioutil.WriteFile(lintOutputFile, report.Bytes(), 0644)
+	} else {
+		_, err = os.Stdout.Write(report.Bytes())
+	}
+	if err != nil {
+		return err
+	}
+
+	errorCount := 0
+	for _, finding := range findings {
+		if finding.Severity == generator.SeverityError {
+			errorCount++
+		}
+	}
+	if errorCount > 0 {
+		return fmt.Errorf("lint found %d errors in %s", errorCount, args[0])
+	}
+
+	return nil
+}
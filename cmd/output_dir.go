@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/random-dwi/helm-doc/generator"
+	"github.com/random-dwi/helm-doc/writer"
+	"helm.sh/helm/v3/pkg/chart"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// indexPage lists the charts and documents the global values of all charts
+const indexPage = "index.md"
+
+// chartPage is the page of a single chart in the output directory
+type chartPage struct {
+	chartDocs *generator.ChartDocs
+	title     string
+	// prefix is the key of the values of the chart as seen by the root chart, e.g. `db`
+	prefix string
+	file   string
+	depth  int
+}
+
+// writeChartDocsDir writes a markdown page per chart and an index page with the dependency tree to dir.
+// Nothing is written if generating the docs fails.
+func writeChartDocsDir(c *chart.Chart, dir string) error {
+
+	chartDocs, err := generator.GenerateChartDocs(c, flags)
+	printWarnings(chartDocs)
+	if err != nil {
+		return err
+	}
+
+	if flags.MigrateValues != "" {
+		if err := migrateValuesFile(chartDocs, flags.VerifyDeprecated, flags.MigrateValues); err != nil {
+			return err
+		}
+	}
+
+	pages := chartPages(chartDocs, chartDocs.Chart.Metadata.Name, "", 0)
+
+	links := map[string]string{}
+	for _, page := range pages {
+		for key := range page.chartDocs.Docs {
+			// global values are linked to the index
+			if !strings.HasPrefix(key, "global.") {
+				links[generator.PrefixKey(page.prefix, key)] = page.file
+			}
+		}
+	}
+	for key := range chartDocs.Globals {
+		links[key] = indexPage
+	}
+
+	rendered := map[string][]byte{}
+
+	var index bytes.Buffer
+	indexWriter := writer.NewLinkedMarkdownWriter(&index, indexPage, "", links)
+	if err := indexWriter.WriteChapter(chartDocs.Chart.Metadata.Name, 1); err != nil {
+		return err
+	}
+
+	for _, page := range pages {
+		if err := indexWriter.WriteIndexEntry(page.title, page.file, page.chartDocs.Chart.Metadata, page.depth); err != nil {
+			return err
+		}
+
+		var content bytes.Buffer
+		gen := writer.NewLinkedMarkdownWriter(&content, page.file, page.prefix, links)
+		if err := gen.WriteMetaData(page.chartDocs.Chart.Metadata, page.chartDocs.Declaration, 1, 0); err != nil {
+			return err
+		}
+		if err := gen.WriteDocs(page.chartDocs.Docs); err != nil {
+			return err
+		}
+		rendered[page.file] = content.Bytes()
+	}
+
+	if _, err := fmt.Fprintln(&index); err != nil {
+		return err
+	}
+	if err := indexWriter.WriteGlobals(chartDocs.Globals, 2); err != nil {
+		return err
+	}
+	rendered[indexPage] = index.Bytes()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for file, content := range rendered {
+		if err := ioutil.WriteFile(filepath.Join(dir, file), content, 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// chartPages returns the pages of a chart and its dependencies depth first.
+// Pages are named after the keys of the values of the charts, e.g. `app.db.md`, so aliases get pages of their own.
+func chartPages(chartDocs *generator.ChartDocs, rootName string, prefix string, depth int) []chartPage {
+
+	title := generator.ValuesKey(chartDocs.Chart.Metadata, chartDocs.Declaration)
+
+	file := rootName + ".md"
+	if depth > 0 {
+		prefix = generator.PrefixKey(prefix, title)
+		file = fmt.Sprintf("%s.%s.md", rootName, prefix)
+	}
+
+	pages := []chartPage{{chartDocs: chartDocs, title: title, prefix: prefix, file: file, depth: depth}}
+
+	for _, dependency := range chartDocs.Dependencies {
+		pages = append(pages, chartPages(dependency, rootName, prefix, depth+1)...)
+	}
+
+	return pages
+}
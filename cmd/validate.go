@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"errors"
+	"github.com/random-dwi/helm-doc/generator"
+	"github.com/random-dwi/helm-doc/output"
+	"github.com/spf13/cobra"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"io/ioutil"
+)
+
+var validateValuesFiles []string
+
+var validateCmd = &cobra.Command{
+	Use:   "validate [flags] CHART",
+	Short: "validate values files of users against the documented values of a helm chart",
+	Long: "reports keys of values files which are neither documented nor have a default in the chart or its dependencies,\n" +
+		"e.g. misspelled keys like `resouces` helm silently ignores, together with the most similar known key.\n" +
+		"values not matching the type, enum or pattern of their definition are reported as well.",
+	RunE: runValidate,
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+
+	f := validateCmd.Flags()
+	f.StringSliceVarP(&validateValuesFiles, "values", "f", nil, "values file to validate (can be repeated)")
+}
+
+func runValidate(cmd *cobra.Command, args []string) error {
+	if len(args) < 1 {
+		return errors.New("chart is required")
+	}
+	if len(validateValuesFiles) == 0 {
+		return errors.New("--values is required")
+	}
+
+	// from here on errors are not caused by wrong usage
+	cmd.SilenceUsage = true
+
+	chartPath, err := locateChart(args[0])
+	if err != nil {
+		return err
+	}
+
+	c, err := loader.Load(chartPath)
+	if err != nil {
+		return err
+	}
+
+	var errs generator.Errors
+	for _, file := range validateValuesFiles {
+		rawValues, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		if err := generator.ValidateValues(c, rawValues, file, flags); err != nil {
+			nested, isErrors := err.(generator.Errors)
+			if !isErrors {
+				return err
+			}
+			errs = append(errs, nested...)
+			continue
+		}
+		output.Debugf("values are valid: %s", file)
+	}
+
+	return errs.ErrorOrNil()
+}
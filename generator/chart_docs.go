@@ -0,0 +1,249 @@
+package generator
+
+import (
+	"fmt"
+	"github.com/random-dwi/helm-doc/output"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"strings"
+)
+
+// ChartDocs holds the generated docs of a chart and its dependencies.
+type ChartDocs struct {
+	Chart *chart.Chart
+	// Declaration is the declaration of a dependency in its parent with alias, condition and tags, if declared
+	Declaration  *chart.Dependency
+	Docs         map[string]*ConfigDoc
+	Dependencies []*ChartDocs
+	// Globals are the global values of all charts of the tree, only set for the root chart
+	Globals map[string]*ConfigDoc
+	// Warnings are the problems of all charts of the tree which do not fail the docs, only set for the root chart
+	Warnings Errors
+}
+
+// ChartTree holds the relations of a chart and its dependencies
+type ChartTree struct {
+	// Parents maps the dependencies to their parent chart
+	Parents map[*chart.Chart]*chart.Chart
+	// Declarations maps the dependencies to their declaration in the parent, if declared
+	Declarations map[*chart.Chart]*chart.Dependency
+}
+
+func newChartTree() *ChartTree {
+	return &ChartTree{Parents: map[*chart.Chart]*chart.Chart{}, Declarations: map[*chart.Chart]*chart.Dependency{}}
+}
+
+// ValuesKey returns the key of the values of a dependency in its parent, which is the alias if given
+func (t *ChartTree) ValuesKey(c *chart.Chart) string {
+	return valuesKey(c, t.Declarations[c])
+}
+
+func valuesKey(c *chart.Chart, declaration *chart.Dependency) string {
+	return ValuesKey(c.Metadata, declaration)
+}
+
+// ValuesKey returns the key of the values of a chart in its parent, which is the alias of its declaration if given.
+// Writers use it as the title of dependencies, so aliases of the same chart are told apart.
+func ValuesKey(metaData *chart.Metadata, declaration *chart.Dependency) string {
+	if declaration != nil && declaration.Alias != "" {
+		return declaration.Alias
+	}
+	return metaData.Name
+}
+
+// PrefixKey returns the key of a value of a dependency as seen by the root chart, where prefix is the key of the values
+// of the dependency, e.g. `db.port` for `port`. Global values are the same for all charts.
+func PrefixKey(prefix string, key string) string {
+	if prefix == "" || strings.HasPrefix(key, globalPrefix) {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// dependencyInstance is a dependency as used by its parent, a chart in charts/ is used several times if it has several aliases
+type dependencyInstance struct {
+	chart       *chart.Chart
+	declaration *chart.Dependency
+}
+
+// GenerateChartDocs generates docs for a chart and all of its dependencies.
+// Problems of all charts are collected before returning, so the error may hold several of them.
+// Problems of dependencies are only warnings unless `flags.VerifyDependencies` is set, warnings are returned with the docs.
+func GenerateChartDocs(c *chart.Chart, flags CommandFlags) (*ChartDocs, error) {
+
+	var errs, warnings Errors
+
+	chartDocs := generateChartDocs(c, newChartTree(), nil, "", flags, &errs, &warnings)
+	chartDocs.Globals = collectGlobals(chartDocs, flags, &errs, &warnings)
+	if flags.VerifyDeprecated != "" {
+		verifyDeprecated(chartDocs, flags, &errs, &warnings)
+	}
+
+	chartDocs.Warnings = warnings
+
+	return chartDocs, errs.ErrorOrNil()
+}
+
+// generateChartDocs collects the problems of a chart into errs and those which do not fail the docs into warnings.
+// The files of problems are prefixed with the path of the chart, e.g. `charts/db/`.
+func generateChartDocs(c *chart.Chart, tree *ChartTree, parent *chart.Chart, path string, flags CommandFlags, errs *Errors, warnings *Errors) *ChartDocs {
+
+	tree.Parents[c] = parent
+
+	output.Debugf("generating docs for %s:%s", c.Metadata.Name, c.Metadata.Version)
+	docs, err := GenerateDocs(c, dependencyNames(c), tree, flags, warnings)
+
+	if err != nil {
+		collected := errs
+		if parent != nil && !flags.VerifyDependencies {
+			collected = warnings
+		}
+		nested, isErrors := err.(Errors)
+		if !isErrors {
+			nested = Errors{err}
+		}
+		for _, nestedErr := range nested {
+			if validationError, isValidationError := nestedErr.(*ValidationError); isValidationError && path != "" {
+				validationError.File = path + validationError.File
+				files := map[string][]string{}
+				for key, keyFiles := range validationError.Files {
+					for _, file := range keyFiles {
+						// conditions are found in the Chart.yaml of the parent, which is named after the parent already
+						if strings.HasPrefix(file, "templates/") {
+							file = path + file
+						}
+						files[key] = append(files[key], file)
+					}
+				}
+				validationError.Files = files
+			}
+		}
+		*collected = append(*collected, nested...)
+	}
+
+	chartDocs := &ChartDocs{Chart: c, Declaration: tree.Declarations[c], Docs: docs}
+
+	for _, dependency := range dependencyInstances(c) {
+		if isLibraryChart(dependency.chart) {
+			output.Debugf("skipping library chart %s:%s", dependency.chart.Metadata.Name, dependency.chart.Metadata.Version)
+			continue
+		}
+		tree.Declarations[dependency.chart] = dependency.declaration
+		dependencyPath := path + "charts/" + dependency.chart.Metadata.Name + "/"
+		chartDocs.Dependencies = append(chartDocs.Dependencies, generateChartDocs(dependency.chart, tree, c, dependencyPath, flags, errs, warnings))
+	}
+
+	if missing := missingDependencies(c); len(missing) > 0 {
+		*warnings = append(*warnings, &ValidationError{
+			Chart:   fmt.Sprintf("%s:%s", c.Metadata.Name, c.Metadata.Version),
+			Rule:    RuleMissingDependency,
+			Message: "dependencies not found in charts/ (hint: running `helm dependency update` may help)",
+			File:    path + chartFileName(c),
+			Keys:    missing,
+		})
+	}
+
+	return chartDocs
+}
+
+// chartFileName returns the file declaring the dependencies of a chart
+func chartFileName(c *chart.Chart) string {
+	if c.Metadata.APIVersion == chart.APIVersionV1 {
+		return "requirements.yaml"
+	}
+	return chartutil.ChartfileName
+}
+
+// dependencyInstances pairs the dependencies in charts/ with their declarations in Chart.yaml or requirements.yaml.
+// Dependencies declared with several aliases are copied, so every alias is documented on its own.
+// Dependencies in charts/ without declaration are used with their name.
+func dependencyInstances(c *chart.Chart) []dependencyInstance {
+
+	var instances []dependencyInstance
+	var declared []*chart.Chart
+
+	for _, declaration := range c.Metadata.Dependencies {
+		for _, dependency := range c.Dependencies() {
+			if dependency.Metadata.Name != declaration.Name {
+				continue
+			}
+			instance := dependency
+			if containsChart(declared, dependency) {
+				copied := *dependency
+				instance = &copied
+			}
+			declared = append(declared, dependency)
+			instances = append(instances, dependencyInstance{chart: instance, declaration: declaration})
+			break
+		}
+	}
+
+	for _, dependency := range c.Dependencies() {
+		if !containsChart(declared, dependency) {
+			instances = append(instances, dependencyInstance{chart: dependency})
+		}
+	}
+
+	return instances
+}
+
+func containsChart(charts []*chart.Chart, c *chart.Chart) bool {
+	for _, existing := range charts {
+		if existing == c {
+			return true
+		}
+	}
+	return false
+}
+
+// dependencyNames returns the keys of the values of the dependencies in charts/ and of those declared in Chart.yaml or requirements.yaml,
+// which are the aliases if given. The values of dependencies are documented by the dependencies themselves.
+func dependencyNames(c *chart.Chart) []string {
+
+	var names []string
+
+	for _, dependency := range dependencyInstances(c) {
+		if name := valuesKey(dependency.chart, dependency.declaration); !containsString(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	// dependencies missing in charts/
+	for _, declaration := range c.Metadata.Dependencies {
+		name := declaration.Name
+		if declaration.Alias != "" {
+			name = declaration.Alias
+		}
+		if !containsString(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// missingDependencies returns the dependencies declared in Chart.yaml or requirements.yaml that are not in charts/
+func missingDependencies(c *chart.Chart) []string {
+
+	var missing []string
+
+	for _, declared := range c.Metadata.Dependencies {
+		found := false
+		for _, dependency := range c.Dependencies() {
+			if dependency.Metadata.Name == declared.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, declared.Name)
+		}
+	}
+
+	return missing
+}
+
+// library charts provide templates only, they cannot be configured by values
+func isLibraryChart(c *chart.Chart) bool {
+	return c.Metadata.Type == "library"
+}
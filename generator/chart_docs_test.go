@@ -0,0 +1,39 @@
+package generator
+
+import (
+	"helm.sh/helm/v3/pkg/chart"
+	"reflect"
+	"testing"
+)
+
+func Test_dependencyInstances(t *testing.T) {
+
+	db := &chart.Chart{Metadata: &chart.Metadata{Name: "db"}}
+	cache := &chart.Chart{Metadata: &chart.Metadata{Name: "cache"}}
+
+	c := &chart.Chart{Metadata: &chart.Metadata{Name: "app", Dependencies: []*chart.Dependency{
+		{Name: "db", Alias: "primary", Condition: "primary.enabled"},
+		{Name: "db", Alias: "replica", Tags: []string{"replication"}},
+		{Name: "missing"},
+	}}}
+	c.SetDependencies(db, cache)
+
+	instances := dependencyInstances(c)
+
+	if len(instances) != 3 {
+		t.Fatalf("dependencyInstances() = %v, want two aliases of db and cache", instances)
+	}
+	if instances[0].chart != db || instances[0].declaration.Alias != "primary" {
+		t.Errorf("dependencyInstances()[0] = %v, want db as primary", instances[0])
+	}
+	if instances[1].chart == db || instances[1].chart.Metadata != db.Metadata || instances[1].declaration.Alias != "replica" {
+		t.Errorf("dependencyInstances()[1] = %v, want a copy of db as replica", instances[1])
+	}
+	if instances[2].chart != cache || instances[2].declaration != nil {
+		t.Errorf("dependencyInstances()[2] = %v, want undeclared cache", instances[2])
+	}
+
+	if got, want := dependencyNames(c), []string{"primary", "replica", "cache", "missing"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("dependencyNames() = %v, want %v", got, want)
+	}
+}
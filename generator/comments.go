@@ -0,0 +1,195 @@
+package generator
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/chart"
+	"regexp"
+	"strings"
+)
+
+// `# -- description` directly above a key, optionally starting with a type like `# -- (int) description`
+var descriptionCommentRegex = regexp.MustCompile(`^#\s*--\s+(?:\((\w+)\)\s*)?(.*)$`)
+
+// `## @param key [modifiers] description` anywhere in the file
+var paramCommentRegex = regexp.MustCompile(`^##\s*@param\s+(\S+)\s+(?:\[[^\]]*\]\s*)?(.*)$`)
+
+var arrayIndexRegex = regexp.MustCompile(`\[\d+\]`)
+
+// types used in comments mapped to definition types
+var commentTypes = map[string]string{
+	"string": "string",
+	"int":    "integer",
+	"float":  "number",
+	"bool":   "boolean",
+	"object": "object",
+	"dict":   "object",
+	"list":   "array",
+}
+
+// parseValueComments reads definitions from the comments of a values.yaml.
+// The returned definitions have the same structure as a definitions.yaml.
+func parseValueComments(rawValues []byte) (map[string]interface{}, error) {
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(rawValues, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse yaml: %s", err)
+	}
+
+	definitions := map[string]interface{}{}
+
+	if root.Kind != yaml.DocumentNode || root.Content[0].Kind != yaml.MappingNode {
+		return definitions, nil
+	}
+
+	// comments above the first key end up in the document if separated by an empty line
+	definitions = commentDefinitions(root.Content[0], root.HeadComment)
+
+	for _, line := range strings.Split(string(rawValues), "\n") {
+		match := paramCommentRegex.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		key := arrayIndexRegex.ReplaceAllString(match[1], "[]")
+		definitions = mergeDefinitions(definitions, nestDefinition(strings.Split(key, "."), strings.TrimSpace(match[2])))
+	}
+
+	return definitions, nil
+}
+
+// withCommentDefinitions merges the definitions from the comments of values.yaml into definitions, which win on conflicts
+func withCommentDefinitions(c *chart.Chart, definitions map[string]interface{}, flags CommandFlags) (map[string]interface{}, error) {
+
+	rawValues := findRawValues(c)
+
+	if !flags.ParseComments || rawValues == nil {
+		return definitions, nil
+	}
+
+	commentDefinitions, err := parseValueComments(rawValues)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read values for %s:%s: %v", c.Metadata.Name, c.Metadata.Version, err)
+	}
+
+	return mergeDefinitions(commentDefinitions, definitions), nil
+}
+
+// commentDefinitions collects `# --` descriptions of a mapping node and its children.
+// If a key and its children are documented, the children win as definitions cannot document both.
+func commentDefinitions(mapping *yaml.Node, headComment string) map[string]interface{} {
+
+	definitions := map[string]interface{}{}
+
+	for i := 0; i < len(mapping.Content)-1; i += 2 {
+		keyNode := mapping.Content[i]
+		valueNode := mapping.Content[i+1]
+
+		comment := keyNode.HeadComment
+		if i == 0 && comment == "" {
+			comment = headComment
+		}
+
+		var children interface{}
+		switch valueNode.Kind {
+		case yaml.MappingNode:
+			if childDefinitions := commentDefinitions(valueNode, ""); len(childDefinitions) > 0 {
+				children = childDefinitions
+			}
+		case yaml.SequenceNode:
+			if len(valueNode.Content) > 0 && valueNode.Content[0].Kind == yaml.MappingNode {
+				// comments above the first key of an array element end up in the element
+				element := valueNode.Content[0]
+				if childDefinitions := commentDefinitions(element, element.HeadComment); len(childDefinitions) > 0 {
+					children = []interface{}{childDefinitions}
+				}
+			}
+		}
+
+		if children != nil {
+			definitions[keyNode.Value] = children
+		} else if definition := parseDescriptionComment(comment); definition != nil {
+			definitions[keyNode.Value] = definition
+		}
+	}
+
+	return definitions
+}
+
+// parseDescriptionComment returns a description or a structured definition if a type is given
+func parseDescriptionComment(comment string) interface{} {
+
+	var description []string
+	var commentType string
+	var found = false
+
+	for _, line := range strings.Split(comment, "\n") {
+		line = strings.TrimSpace(line)
+		if match := descriptionCommentRegex.FindStringSubmatch(line); match != nil {
+			// only the last description above a key counts
+			found = true
+			commentType = match[1]
+			description = []string{strings.TrimSpace(match[2])}
+		} else if found && strings.HasPrefix(line, "#") {
+			description = append(description, strings.TrimSpace(strings.TrimLeft(line, "#")))
+		}
+	}
+
+	if !found {
+		return nil
+	}
+
+	joined := strings.TrimSpace(strings.Join(description, " "))
+
+	if definitionType, exists := commentTypes[commentType]; exists {
+		return map[string]interface{}{"description": joined, "type": definitionType}
+	}
+
+	return joined
+}
+
+// nestDefinition turns a key path like `hosts[].name` into nested definitions
+func nestDefinition(keys []string, definition interface{}) map[string]interface{} {
+
+	var nested = definition
+	if len(keys) > 1 {
+		nested = nestDefinition(keys[1:], definition)
+	}
+
+	baseKey, isArray := isArrayKey(keys[0])
+	if isArray && len(keys) > 1 {
+		nested = []interface{}{nested}
+	}
+
+	return map[string]interface{}{baseKey: nested}
+}
+
+// mergeDefinitions merges definitions into base, definitions win on conflicts
+func mergeDefinitions(base map[string]interface{}, definitions map[string]interface{}) map[string]interface{} {
+
+	for key, definition := range definitions {
+		baseDefinition := base[key]
+
+		baseArray, baseIsArray := baseDefinition.([]interface{})
+		defArray, defIsArray := definition.([]interface{})
+		if baseIsArray && defIsArray && len(baseArray) == 1 && len(defArray) == 1 {
+			baseDefinition = baseArray[0]
+			definition = defArray[0]
+		}
+
+		baseMap, baseIsMap := baseDefinition.(map[string]interface{})
+		defMap, defIsMap := definition.(map[string]interface{})
+		if !baseIsMap || !defIsMap || isStructuredDefinition(baseMap) || isStructuredDefinition(defMap) {
+			base[key] = definitions[key]
+			continue
+		}
+
+		merged := mergeDefinitions(baseMap, defMap)
+		if baseIsArray {
+			base[key] = []interface{}{merged}
+		} else {
+			base[key] = merged
+		}
+	}
+
+	return base
+}
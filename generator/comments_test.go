@@ -0,0 +1,61 @@
+package generator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_parseValueComments(t *testing.T) {
+	tests := []struct {
+		name   string
+		values string
+		want   string
+	}{
+		{name: "no_comments", values: "key: 1\n", want: `{}`},
+		{name: "description", values: "# -- docs\nkey: 1\n", want: `{"key": "docs"}`},
+		{name: "description_separated_by_empty_line", values: "# -- docs\n\nkey: 1\n", want: `{"key": "docs"}`},
+		{name: "multiline_description", values: "# some comment\n# -- first line\n# second line\nkey: 1\n", want: `{"key": "first line second line"}`},
+		{name: "separator", values: "# ------\nkey: 1\n", want: `{}`},
+		{name: "typed_description", values: "# -- (int) docs\nkey: 1\n", want: `{"key": {"description": "docs", "type": "integer"}}`},
+		{name: "nested", values: "parent:\n  # -- docs\n  child: 1\n", want: `{"parent": {"child": "docs"}}`},
+		{name: "children_win", values: "# -- parent docs\nparent:\n  # -- docs\n  child: 1\n", want: `{"parent": {"child": "docs"}}`},
+		{name: "array", values: "array:\n  # -- docs1\n  - child1: 1\n    # -- docs2\n    child2: 2\n", want: `{"array": [{"child1": "docs1", "child2": "docs2"}]}`},
+		{name: "param", values: "## @param parent.child [string] docs\nparent:\n  child: 1\n", want: `{"parent": {"child": "docs"}}`},
+		{name: "param_array", values: "## @param array[0].child docs\narray:\n  - child: 1\n", want: `{"array": [{"child": "docs"}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseValueComments([]byte(tt.values))
+			if err != nil {
+				t.Errorf("parseValueComments() error = %v", err)
+				return
+			}
+			if want := parseJson(tt.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("parseValueComments() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func Test_mergeDefinitions(t *testing.T) {
+	tests := []struct {
+		name        string
+		base        string
+		definitions string
+		want        string
+	}{
+		{name: "disjoint", base: `{"key1": "docs1"}`, definitions: `{"key2": "docs2"}`, want: `{"key1": "docs1", "key2": "docs2"}`},
+		{name: "definitions_win", base: `{"key": "base"}`, definitions: `{"key": "docs"}`, want: `{"key": "docs"}`},
+		{name: "nested", base: `{"parent": {"child1": "docs1"}}`, definitions: `{"parent": {"child2": "docs2"}}`, want: `{"parent": {"child1": "docs1", "child2": "docs2"}}`},
+		{name: "leaf_wins", base: `{"parent": {"child": "docs"}}`, definitions: `{"parent": "parent docs"}`, want: `{"parent": "parent docs"}`},
+		{name: "structured_wins", base: `{"parent": {"child": "docs"}}`, definitions: `{"parent": {"description": "docs", "type": "object"}}`, want: `{"parent": {"description": "docs", "type": "object"}}`},
+		{name: "array", base: `{"array": [{"child1": "docs1"}]}`, definitions: `{"array": [{"child2": "docs2"}]}`, want: `{"array": [{"child1": "docs1", "child2": "docs2"}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, want := mergeDefinitions(parseJson(tt.base), parseJson(tt.definitions)), parseJson(tt.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("mergeDefinitions() = %v, want %v", got, want)
+			}
+		})
+	}
+}
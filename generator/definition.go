@@ -0,0 +1,244 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// attributes allowed in a structured definition like `{description: ..., type: integer, required: true}`
+var definitionAttributes = []string{"description", "type", "required", "enum", "pattern", "deprecated", "replacedBy"}
+
+// types allowed for the `type` attribute, named like in json schema
+var definitionTypes = []string{"string", "integer", "number", "boolean", "object", "array"}
+
+// a structured definition is a map with a string description and only known attributes of the right shape.
+// Otherwise the map documents child keys named like attributes, e.g. `type: type of the service` or `required: set when TLS is on`.
+// A pattern is only an attribute of a definition of type string, as free text is a valid regular expression as well.
+func asStructuredDefinition(value interface{}) (map[string]interface{}, bool) {
+
+	defMap, isMap := value.(map[string]interface{})
+	if !isMap {
+		return nil, false
+	}
+
+	if _, hasDescription := defMap["description"].(string); !hasDescription {
+		return nil, false
+	}
+
+	for key := range defMap {
+		if !containsString(definitionAttributes, key) {
+			return nil, false
+		}
+	}
+
+	for key, attribute := range defMap {
+		if !isDefinitionAttribute(key, attribute, defMap) {
+			return nil, false
+		}
+	}
+
+	return defMap, true
+}
+
+func isDefinitionAttribute(key string, value interface{}, definition map[string]interface{}) bool {
+	switch key {
+	case "type":
+		definitionType, isString := value.(string)
+		return isString && containsString(definitionTypes, definitionType)
+	case "required":
+		_, isBool := value.(bool)
+		return isBool
+	case "enum":
+		enum, isArray := value.([]interface{})
+		return isArray && len(enum) > 0
+	case "pattern":
+		_, isString := value.(string)
+		return isString && definition["type"] == "string"
+	case "description", "deprecated", "replacedBy":
+		_, isString := value.(string)
+		return isString
+	}
+	return false
+}
+
+func isStructuredDefinition(value interface{}) bool {
+	_, isStructured := asStructuredDefinition(value)
+	return isStructured
+}
+
+// a definition leaf documents a key and all of its children
+func isDefinitionLeaf(value interface{}) bool {
+	_, isString := value.(string)
+	return isString || isStructuredDefinition(value)
+}
+
+func parseStructuredDefinition(globalKey string, definition map[string]interface{}) (*ConfigDoc, error) {
+
+	configDoc := &ConfigDoc{Description: definition["description"].(string)}
+
+	if value, exists := definition["type"]; exists {
+		definitionType, isString := value.(string)
+		if !isString || !containsString(definitionTypes, definitionType) {
+			return nil, fmt.Errorf("definition type has to be one of %v: %s (value: %v)", definitionTypes, globalKey, value)
+		}
+		configDoc.Type = definitionType
+	}
+
+	if value, exists := definition["required"]; exists {
+		required, isBool := value.(bool)
+		if !isBool {
+			return nil, fmt.Errorf("definition required has to be a boolean: %s (value: %v)", globalKey, value)
+		}
+		configDoc.Required = required
+	}
+
+	if value, exists := definition["enum"]; exists {
+		enum, isArray := value.([]interface{})
+		if !isArray || len(enum) == 0 {
+			return nil, fmt.Errorf("definition enum has to be a non empty array: %s (value: %v)", globalKey, value)
+		}
+		configDoc.Enum = enum
+	}
+
+	if value, exists := definition["pattern"]; exists {
+		pattern, isString := value.(string)
+		if !isString {
+			return nil, fmt.Errorf("definition pattern has to be a string: %s (value: %v)", globalKey, value)
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("definition pattern is not a valid regular expression: %s (value: %v)", globalKey, value)
+		}
+		configDoc.Pattern = pattern
+	}
+
+	if value, exists := definition["deprecated"]; exists {
+		deprecated, isString := value.(string)
+		if !isString || deprecated == "" {
+			return nil, fmt.Errorf("definition deprecated has to be a non empty string: %s (value: %v)", globalKey, value)
+		}
+		configDoc.Deprecated = deprecated
+	}
+
+	if value, exists := definition["replacedBy"]; exists {
+		replacedBy, isString := value.(string)
+		if !isString || replacedBy == "" {
+			return nil, fmt.Errorf("definition replacedBy has to be a non empty string: %s (value: %v)", globalKey, value)
+		}
+		configDoc.ReplacedBy = replacedBy
+	}
+
+	return configDoc, nil
+}
+
+// check default and example values against the type, enum and pattern of their definition
+func validateTypes(docs map[string]*ConfigDoc) []string {
+
+	var typeErrors []string
+
+	for globalKey, configDoc := range docs {
+		if err := validateValue(configDoc, configDoc.DefaultValue); err != nil {
+			typeErrors = append(typeErrors, fmt.Sprintf("%s: default %v", globalKey, err))
+		}
+		if err := validateValue(configDoc, configDoc.ExampleValue); err != nil {
+			typeErrors = append(typeErrors, fmt.Sprintf("%s: example %v", globalKey, err))
+		}
+	}
+
+	sort.Strings(typeErrors)
+
+	return typeErrors
+}
+
+// findUnknownReplacements returns the keys replaced by a key which is neither documented nor the parent of documented keys
+func findUnknownReplacements(docs map[string]*ConfigDoc) []string {
+
+	var unknown []string
+
+	for globalKey, configDoc := range docs {
+		if configDoc.ReplacedBy != "" && !isDocumentedKey(configDoc.ReplacedBy, docs) {
+			unknown = append(unknown, fmt.Sprintf("%s: replaced by %s", globalKey, configDoc.ReplacedBy))
+		}
+	}
+
+	sort.Strings(unknown)
+
+	return unknown
+}
+
+func isDocumentedKey(key string, docs map[string]*ConfigDoc) bool {
+	for docKey := range docs {
+		if docKey == key || strings.HasPrefix(docKey, key+".") || strings.HasPrefix(docKey, key+"[]") {
+			return true
+		}
+	}
+	return false
+}
+
+func validateValue(configDoc *ConfigDoc, value interface{}) error {
+
+	if value == nil {
+		return nil
+	}
+
+	if configDoc.Type != "" && !matchesType(value, configDoc.Type) {
+		return fmt.Errorf("%v is not of type %s", value, configDoc.Type)
+	}
+
+	if len(configDoc.Enum) > 0 {
+		found := false
+		for _, allowed := range configDoc.Enum {
+			if reflect.DeepEqual(allowed, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%v is not one of %v", value, configDoc.Enum)
+		}
+	}
+
+	if configDoc.Pattern != "" {
+		stringValue, isString := value.(string)
+		if isString && !regexp.MustCompile(configDoc.Pattern).MatchString(stringValue) {
+			return fmt.Errorf("%v does not match pattern %s", value, configDoc.Pattern)
+		}
+	}
+
+	return nil
+}
+
+func matchesType(value interface{}, definitionType string) bool {
+	switch definitionType {
+	case "string":
+		_, isString := value.(string)
+		return isString
+	case "boolean":
+		_, isBool := value.(bool)
+		return isBool
+	case "integer":
+		switch typed := value.(type) {
+		case int, int32, int64:
+			return true
+		case float64:
+			return typed == math.Trunc(typed)
+		}
+		return false
+	case "number":
+		switch value.(type) {
+		case int, int32, int64, float64:
+			return true
+		}
+		return false
+	case "object":
+		_, isMap := value.(map[string]interface{})
+		return isMap
+	case "array":
+		_, isArray := value.([]interface{})
+		return isArray
+	}
+	return false
+}
@@ -0,0 +1,179 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+// RuleDeprecatedValue is used for deprecated values set in a values file of a user
+const RuleDeprecatedValue = "deprecated-value"
+
+// verifyDeprecated warns about deprecated values set in the values file of `flags.VerifyDeprecated`
+func verifyDeprecated(chartDocs *ChartDocs, flags CommandFlags, errs *Errors, warnings *Errors) {
+
+	rawValues, err := ioutil.ReadFile(flags.VerifyDeprecated)
+	if err != nil {
+		*errs = append(*errs, err)
+		return
+	}
+	values, err := parseYaml(rawValues)
+	if err != nil {
+		*errs = append(*errs, fmt.Errorf("unable to read %s: %v", flags.VerifyDeprecated, err))
+		return
+	}
+
+	docs := flattenChartDocs(chartDocs, "")
+	chartName := fmt.Sprintf("%s:%s", chartDocs.Chart.Metadata.Name, chartDocs.Chart.Metadata.Version)
+
+	for _, key := range findDeprecatedValues(docs, values) {
+		message := "deprecated value set: " + docs[key].Deprecated
+		if docs[key].ReplacedBy != "" {
+			message += fmt.Sprintf(" (replaced by %s)", docs[key].ReplacedBy)
+		}
+		*warnings = append(*warnings, &ValidationError{Chart: chartName, Rule: RuleDeprecatedValue, Message: message, File: flags.VerifyDeprecated, Keys: []string{key}})
+	}
+}
+
+// findDeprecatedValues returns the deprecated keys set by values, a key is set if it or one of its children is set
+func findDeprecatedValues(docs map[string]*ConfigDoc, values map[string]interface{}) []string {
+
+	var deprecated []string
+	valueKeys := valueLeafKeys("", values)
+
+	for key, configDoc := range docs {
+		if configDoc.Deprecated == "" {
+			continue
+		}
+		for _, valueKey := range valueKeys {
+			if valueKey == key || strings.HasPrefix(valueKey, key+".") || strings.HasPrefix(valueKey, key+"[]") {
+				deprecated = append(deprecated, key)
+				break
+			}
+		}
+	}
+	sort.Strings(deprecated)
+
+	return deprecated
+}
+
+// MigrateValues moves the values of deprecated keys to the keys replacing them, keeping the comments and order of the values file.
+// Values are not moved into arrays or over values set already. It returns the migrated values file and the moved keys.
+func MigrateValues(chartDocs *ChartDocs, rawValues []byte) ([]byte, []string, error) {
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(rawValues, &document); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse yaml: %s", err)
+	}
+	if document.Kind != yaml.DocumentNode || document.Content[0].Kind != yaml.MappingNode {
+		return rawValues, nil, nil
+	}
+	root := document.Content[0]
+
+	docs := flattenChartDocs(chartDocs, "")
+	var keys []string
+	for key, configDoc := range docs {
+		if configDoc.ReplacedBy != "" && !strings.Contains(key+configDoc.ReplacedBy, "[]") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var moved []string
+
+	for _, key := range keys {
+		target := strings.Split(docs[key].ReplacedBy, ".")
+		if !canInsertMappingValue(root, target) {
+			continue
+		}
+		keyNode, valueNode := removeMappingValue(root, strings.Split(key, "."))
+		if valueNode == nil {
+			continue
+		}
+		keyNode.Value = target[len(target)-1]
+		insertMappingValue(root, target, keyNode, valueNode)
+		moved = append(moved, fmt.Sprintf("%s -> %s", key, docs[key].ReplacedBy))
+	}
+
+	var migrated bytes.Buffer
+	encoder := yaml.NewEncoder(&migrated)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&document); err != nil {
+		return nil, nil, fmt.Errorf("unable to serialize values: %v", err)
+	}
+
+	return migrated.Bytes(), moved, nil
+}
+
+// canInsertMappingValue returns false if the key is set already or one of its parents is no mapping
+func canInsertMappingValue(mapping *yaml.Node, keys []string) bool {
+	for i := 1; i <= len(keys); i++ {
+		node := findMappingValue(mapping, keys[:i])
+		if node != nil && (i == len(keys) || node.Kind != yaml.MappingNode) {
+			return false
+		}
+	}
+	return true
+}
+
+func findMappingValue(mapping *yaml.Node, keys []string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != keys[0] {
+			continue
+		}
+		if len(keys) == 1 {
+			return mapping.Content[i+1]
+		}
+		if mapping.Content[i+1].Kind == yaml.MappingNode {
+			return findMappingValue(mapping.Content[i+1], keys[1:])
+		}
+	}
+	return nil
+}
+
+// removeMappingValue removes a key and its value, parents left empty are removed as well
+func removeMappingValue(mapping *yaml.Node, keys []string) (*yaml.Node, *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != keys[0] {
+			continue
+		}
+		if len(keys) == 1 {
+			keyNode, valueNode := mapping.Content[i], mapping.Content[i+1]
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return keyNode, valueNode
+		}
+		child := mapping.Content[i+1]
+		if child.Kind != yaml.MappingNode {
+			return nil, nil
+		}
+		keyNode, valueNode := removeMappingValue(child, keys[1:])
+		if valueNode != nil && len(child.Content) == 0 {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+		}
+		return keyNode, valueNode
+	}
+	return nil, nil
+}
+
+// insertMappingValue adds a key and its value, missing parents are added at the end of their mapping
+func insertMappingValue(mapping *yaml.Node, keys []string, keyNode *yaml.Node, valueNode *yaml.Node) {
+
+	if len(keys) == 1 {
+		mapping.Content = append(mapping.Content, keyNode, valueNode)
+		return
+	}
+
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == keys[0] && mapping.Content[i+1].Kind == yaml.MappingNode {
+			insertMappingValue(mapping.Content[i+1], keys[1:], keyNode, valueNode)
+			return
+		}
+	}
+
+	child := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: keys[0]}, child)
+	insertMappingValue(child, keys[1:], keyNode, valueNode)
+}
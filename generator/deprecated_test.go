@@ -0,0 +1,82 @@
+package generator
+
+import (
+	"helm.sh/helm/v3/pkg/chart"
+	"reflect"
+	"testing"
+)
+
+func Test_findDeprecatedValues(t *testing.T) {
+	docs := map[string]*ConfigDoc{
+		"image.repository": {Deprecated: "use image.repo"},
+		"image.repo":       {},
+		"legacy":           {Deprecated: "not used anymore"},
+		"hosts[].host":     {Deprecated: "use hosts[].name"},
+	}
+	tests := []struct {
+		name   string
+		values string
+		want   []string
+	}{
+		{name: "not_set", values: `{"image": {"repo": "nginx"}}`, want: nil},
+		{name: "set", values: `{"image": {"repository": "nginx"}}`, want: []string{"image.repository"}},
+		{name: "child_set", values: `{"legacy": {"enabled": true}}`, want: []string{"legacy"}},
+		{name: "array_element_set", values: `{"hosts": [{"host": "example.com"}]}`, want: []string{"hosts[].host"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := findDeprecatedValues(docs, parseJson(tt.values)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findDeprecatedValues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_MigrateValues(t *testing.T) {
+	chartDocs := &ChartDocs{
+		Chart: &chart.Chart{Metadata: &chart.Metadata{Name: "app", Version: "1.0.0"}},
+		Docs: map[string]*ConfigDoc{
+			"image.repository": {Deprecated: "use image.repo", ReplacedBy: "image.repo"},
+			"tag":              {Deprecated: "use image.tag", ReplacedBy: "image.tag"},
+			"name":             {Deprecated: "use fullname", ReplacedBy: "fullname"},
+		},
+		Dependencies: []*ChartDocs{{
+			Chart:       &chart.Chart{Metadata: &chart.Metadata{Name: "postgres", Version: "1.0.0"}},
+			Declaration: &chart.Dependency{Name: "postgres", Alias: "db"},
+			Docs: map[string]*ConfigDoc{
+				"port":         {Deprecated: "use service.port", ReplacedBy: "service.port"},
+				"service.port": {},
+			},
+		}},
+	}
+	tests := []struct {
+		name      string
+		values    string
+		want      string
+		wantMoved []string
+	}{
+		{name: "nothing_to_move", values: "replicas: 1\n", want: "replicas: 1\n"},
+		{name: "renamed", values: "image:\n  # my repository\n  repository: nginx\n  pullPolicy: Always\n",
+			want: "image:\n  pullPolicy: Always\n  # my repository\n  repo: nginx\n", wantMoved: []string{"image.repository -> image.repo"}},
+		{name: "moved_into_new_parent", values: "replicas: 1\ntag: \"1.0\"\n",
+			want: "replicas: 1\nimage:\n  tag: \"1.0\"\n", wantMoved: []string{"tag -> image.tag"}},
+		{name: "target_set", values: "name: a\nfullname: b\n", want: "name: a\nfullname: b\n"},
+		{name: "target_parent_no_map", values: "image: nginx\ntag: \"1.0\"\n", want: "image: nginx\ntag: \"1.0\"\n"},
+		{name: "dependency", values: "db:\n  port: 1\n",
+			want: "db:\n  service:\n    port: 1\n", wantMoved: []string{"db.port -> db.service.port"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, moved, err := MigrateValues(chartDocs, []byte(tt.values))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("MigrateValues() = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(moved, tt.wantMoved) {
+				t.Errorf("MigrateValues() moved = %v, want %v", moved, tt.wantMoved)
+			}
+		})
+	}
+}
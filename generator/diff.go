@@ -0,0 +1,254 @@
+package generator
+
+import (
+	"helm.sh/helm/v3/pkg/chart"
+	"reflect"
+	"sort"
+)
+
+// kinds of changes between two versions of a chart
+const (
+	ChangeAdded       = "added"
+	ChangeRemoved     = "removed"
+	ChangeRenamed     = "renamed"
+	ChangeDefault     = "default"
+	ChangeDescription = "description"
+	ChangeDeprecated  = "deprecated"
+)
+
+// Change is a change of a documented value between two versions of a chart.
+type Change struct {
+	Kind string `json:"kind" yaml:"kind"`
+	Key  string `json:"key" yaml:"key"`
+	// OldKey is the key in the old version of renamed values
+	OldKey string      `json:"oldKey,omitempty" yaml:"oldKey,omitempty"`
+	From   interface{} `json:"from,omitempty" yaml:"from,omitempty"`
+	To     interface{} `json:"to,omitempty" yaml:"to,omitempty"`
+	// Description and Example are those of added and deprecated keys in the new version
+	Description string      `json:"description,omitempty" yaml:"description,omitempty"`
+	Example     interface{} `json:"example,omitempty" yaml:"example,omitempty"`
+	// Breaking changes require users to change their values or alter the behavior of existing releases
+	Breaking bool `json:"breaking" yaml:"breaking"`
+}
+
+// Diff holds the changes of the documented values between two versions of a chart.
+type Diff struct {
+	Chart   string    `json:"chart" yaml:"chart"`
+	From    string    `json:"from" yaml:"from"`
+	To      string    `json:"to" yaml:"to"`
+	Changes []*Change `json:"changes" yaml:"changes"`
+}
+
+// DiffCharts compares the documented values of two versions of a chart and its dependencies.
+func DiffCharts(from *chart.Chart, to *chart.Chart, flags CommandFlags) (*Diff, error) {
+
+	diffs, err := DiffVersions([]*chart.Chart{from, to}, flags)
+	if err != nil {
+		return nil, err
+	}
+
+	return diffs[0], nil
+}
+
+// DiffVersions compares each version of a chart with the previous one, the charts are ordered from oldest to newest.
+// The charts are not validated, as old versions may not satisfy the current rules.
+func DiffVersions(charts []*chart.Chart, flags CommandFlags) ([]*Diff, error) {
+
+	flags = withoutVerification(flags)
+
+	var diffs []*Diff
+	var previous map[string]*ConfigDoc
+
+	for i, c := range charts {
+		chartDocs, err := GenerateChartDocs(c, flags)
+		if err != nil {
+			return nil, err
+		}
+		docs := flattenChartDocs(chartDocs, "")
+		if i > 0 {
+			diffs = append(diffs, &Diff{
+				Chart:   c.Metadata.Name,
+				From:    charts[i-1].Metadata.Version,
+				To:      c.Metadata.Version,
+				Changes: diffDocs(previous, docs),
+			})
+		}
+		previous = docs
+	}
+
+	return diffs, nil
+}
+
+// withoutVerification disables the validations of the chart itself, for commands using the docs of charts they do not own.
+// The warnings of the docs are ignored by these commands as well.
+func withoutVerification(flags CommandFlags) CommandFlags {
+	flags.VerifyExamples = false
+	flags.VerifyExamplesRender = false
+	flags.VerifyValues = false
+	flags.VerifyTemplates = false
+	flags.VerifyUsage = false
+	flags.UsedIn = false
+	flags.VerifyDeprecated = ""
+	flags.VerifyDependencies = false
+	return flags
+}
+
+// flattenChartDocs returns the docs of a chart and its dependencies with the keys as seen by the chart, e.g. `db.port`.
+// The keys replacing deprecated values of dependencies are prefixed the same way.
+func flattenChartDocs(chartDocs *ChartDocs, prefix string) map[string]*ConfigDoc {
+
+	docs := map[string]*ConfigDoc{}
+
+	for key, configDoc := range chartDocs.Docs {
+		key = PrefixKey(prefix, key)
+		if configDoc.ReplacedBy != "" && prefix != "" {
+			// the docs of the chart keep the key replacing a value as seen by the chart itself
+			prefixed := *configDoc
+			prefixed.ReplacedBy = PrefixKey(prefix, configDoc.ReplacedBy)
+			configDoc = &prefixed
+		}
+		if _, exists := docs[key]; !exists {
+			docs[key] = configDoc
+		}
+	}
+
+	for _, dependency := range chartDocs.Dependencies {
+		dependencyPrefix := PrefixKey(prefix, valuesKey(dependency.Chart, dependency.Declaration))
+		for key, configDoc := range flattenChartDocs(dependency, dependencyPrefix) {
+			if _, exists := docs[key]; !exists {
+				docs[key] = configDoc
+			}
+		}
+	}
+
+	return docs
+}
+
+// diffDocs returns the changes sorted by key. A removed and an added key are a rename
+// if the removed key is replaced by the added key or they have the same description.
+func diffDocs(from map[string]*ConfigDoc, to map[string]*ConfigDoc) []*Change {
+
+	var changes []*Change
+	var removed, added []string
+
+	for key, fromDoc := range from {
+		toDoc, exists := to[key]
+		if !exists {
+			removed = append(removed, key)
+			continue
+		}
+		if !reflect.DeepEqual(fromDoc.DefaultValue, toDoc.DefaultValue) {
+			changes = append(changes, &Change{Kind: ChangeDefault, Key: key, From: fromDoc.DefaultValue, To: toDoc.DefaultValue,
+				Breaking: defaultChangeBreaking(fromDoc.DefaultValue, toDoc.DefaultValue)})
+		}
+		if fromDoc.Description != toDoc.Description {
+			changes = append(changes, &Change{Kind: ChangeDescription, Key: key, From: fromDoc.Description, To: toDoc.Description})
+		}
+		if toDoc.Deprecated != "" && fromDoc.Deprecated == "" {
+			changes = append(changes, &Change{Kind: ChangeDeprecated, Key: key, Description: toDoc.Deprecated, To: nilIfEmpty(toDoc.ReplacedBy)})
+		}
+	}
+	for key := range to {
+		if _, exists := from[key]; !exists {
+			added = append(added, key)
+		}
+	}
+	sort.Strings(removed)
+	sort.Strings(added)
+
+	renamed := map[string]bool{}
+
+	for _, oldKey := range removed {
+		newKey := findRename(from[oldKey], removed, from, added, to, renamed)
+		if newKey == "" {
+			changes = append(changes, &Change{Kind: ChangeRemoved, Key: oldKey, From: from[oldKey].DefaultValue, Breaking: true})
+			continue
+		}
+		renamed[newKey] = true
+		changes = append(changes, &Change{Kind: ChangeRenamed, Key: newKey, OldKey: oldKey, From: from[oldKey].DefaultValue, To: to[newKey].DefaultValue, Breaking: true})
+	}
+
+	for _, key := range added {
+		if renamed[key] {
+			continue
+		}
+		// existing values of users lack required keys without default
+		toDoc := to[key]
+		changes = append(changes, &Change{Kind: ChangeAdded, Key: key, To: toDoc.DefaultValue, Description: toDoc.Description, Example: toDoc.ExampleValue,
+			Breaking: toDoc.Required && toDoc.DefaultValue == nil})
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+
+	return changes
+}
+
+// findRename returns the added key replacing a removed key. Without replacedBy the description has to be unique
+// among the removed and the added keys, generic descriptions like "enable the feature" or TODO tell nothing.
+func findRename(fromDoc *ConfigDoc, removed []string, from map[string]*ConfigDoc, added []string, to map[string]*ConfigDoc, renamed map[string]bool) string {
+
+	if _, exists := to[fromDoc.ReplacedBy]; exists && fromDoc.ReplacedBy != "" {
+		return fromDoc.ReplacedBy
+	}
+
+	if fromDoc.Description == "" || fromDoc.Description == TodoPlaceholder {
+		return ""
+	}
+
+	var matches []string
+	for _, newKey := range added {
+		if !renamed[newKey] && fromDoc.Description == to[newKey].Description {
+			matches = append(matches, newKey)
+		}
+	}
+	if len(matches) != 1 {
+		return ""
+	}
+
+	removedMatches := 0
+	for _, oldKey := range removed {
+		if from[oldKey].Description == fromDoc.Description {
+			removedMatches++
+		}
+	}
+	if removedMatches != 1 {
+		return ""
+	}
+
+	return matches[0]
+}
+
+func nilIfEmpty(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+// defaultChangeBreaking returns false if there was no default before or a map only got additional keys
+func defaultChangeBreaking(from interface{}, to interface{}) bool {
+
+	if from == nil {
+		return false
+	}
+
+	fromMap, isFromMap := from.(map[string]interface{})
+	toMap, isToMap := to.(map[string]interface{})
+	if !isFromMap || !isToMap {
+		return true
+	}
+
+	for key, fromValue := range fromMap {
+		toValue, exists := toMap[key]
+		if !exists {
+			return true
+		}
+		if !reflect.DeepEqual(fromValue, toValue) && defaultChangeBreaking(fromValue, toValue) {
+			return true
+		}
+	}
+
+	return false
+}
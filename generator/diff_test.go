@@ -0,0 +1,114 @@
+package generator
+
+import (
+	"helm.sh/helm/v3/pkg/chart"
+	"reflect"
+	"testing"
+)
+
+func Test_diffDocs(t *testing.T) {
+	tests := []struct {
+		name string
+		from map[string]*ConfigDoc
+		to   map[string]*ConfigDoc
+		want []*Change
+	}{
+		{name: "unchanged", from: map[string]*ConfigDoc{"a": {Description: "a", DefaultValue: 1}}, to: map[string]*ConfigDoc{"a": {Description: "a", DefaultValue: 1}}, want: nil},
+		{name: "added", from: map[string]*ConfigDoc{}, to: map[string]*ConfigDoc{"a": {Description: "a", DefaultValue: 1, ExampleValue: 2}},
+			want: []*Change{{Kind: ChangeAdded, Key: "a", To: 1, Description: "a", Example: 2}}},
+		{name: "added_required", from: map[string]*ConfigDoc{}, to: map[string]*ConfigDoc{"a": {Description: "a", Required: true}},
+			want: []*Change{{Kind: ChangeAdded, Key: "a", Description: "a", Breaking: true}}},
+		{name: "removed", from: map[string]*ConfigDoc{"a": {Description: "a", DefaultValue: 1}}, to: map[string]*ConfigDoc{},
+			want: []*Change{{Kind: ChangeRemoved, Key: "a", From: 1, Breaking: true}}},
+		{name: "renamed_by_description", from: map[string]*ConfigDoc{"image.repository": {Description: "image repository", DefaultValue: "nginx"}},
+			to:   map[string]*ConfigDoc{"image.repo": {Description: "image repository", DefaultValue: "nginx"}},
+			want: []*Change{{Kind: ChangeRenamed, Key: "image.repo", OldKey: "image.repository", From: "nginx", To: "nginx", Breaking: true}}},
+		{name: "not_renamed_by_todo", from: map[string]*ConfigDoc{"a": {Description: TodoPlaceholder}}, to: map[string]*ConfigDoc{"b": {Description: TodoPlaceholder}},
+			want: []*Change{{Kind: ChangeRemoved, Key: "a", Breaking: true}, {Kind: ChangeAdded, Key: "b", Description: TodoPlaceholder}}},
+		{name: "not_renamed_by_ambiguous_description", from: map[string]*ConfigDoc{"metrics.enabled": {Description: "enable the feature"}},
+			to: map[string]*ConfigDoc{"ingress.enabled": {Description: "enable the feature"}, "tls.enabled": {Description: "enable the feature"}},
+			want: []*Change{{Kind: ChangeAdded, Key: "ingress.enabled", Description: "enable the feature"},
+				{Kind: ChangeRemoved, Key: "metrics.enabled", Breaking: true}, {Kind: ChangeAdded, Key: "tls.enabled", Description: "enable the feature"}}},
+		{name: "not_renamed_by_ambiguous_removed_description", from: map[string]*ConfigDoc{"a.enabled": {Description: "enable the feature"}, "b.enabled": {Description: "enable the feature"}},
+			to: map[string]*ConfigDoc{"c.enabled": {Description: "enable the feature"}},
+			want: []*Change{{Kind: ChangeRemoved, Key: "a.enabled", Breaking: true}, {Kind: ChangeRemoved, Key: "b.enabled", Breaking: true},
+				{Kind: ChangeAdded, Key: "c.enabled", Description: "enable the feature"}}},
+		{name: "not_renamed_by_name_and_default", from: map[string]*ConfigDoc{"metrics.enabled": {Description: "enable metrics", DefaultValue: false}},
+			to: map[string]*ConfigDoc{"ingress.enabled": {Description: "enable ingress", DefaultValue: false}},
+			want: []*Change{{Kind: ChangeAdded, Key: "ingress.enabled", To: false, Description: "enable ingress"},
+				{Kind: ChangeRemoved, Key: "metrics.enabled", From: false, Breaking: true}}},
+		{name: "default_and_description", from: map[string]*ConfigDoc{"a": {Description: "a", DefaultValue: 1}}, to: map[string]*ConfigDoc{"a": {Description: "b", DefaultValue: 2}},
+			want: []*Change{{Kind: ChangeDefault, Key: "a", From: 1, To: 2, Breaking: true}, {Kind: ChangeDescription, Key: "a", From: "a", To: "b"}}},
+		{name: "deprecated", from: map[string]*ConfigDoc{"a": {Description: "a"}}, to: map[string]*ConfigDoc{"a": {Description: "a", Deprecated: "use b instead", ReplacedBy: "b"}},
+			want: []*Change{{Kind: ChangeDeprecated, Key: "a", Description: "use b instead", To: "b"}}},
+		{name: "renamed_by_replacement", from: map[string]*ConfigDoc{"a": {Description: "a", ReplacedBy: "c"}, "c": {Description: "c"}}, to: map[string]*ConfigDoc{"c": {Description: "c"}},
+			want: []*Change{{Kind: ChangeRenamed, Key: "c", OldKey: "a", Breaking: true}}},
+		{name: "new_default", from: map[string]*ConfigDoc{"a": {Description: "a"}}, to: map[string]*ConfigDoc{"a": {Description: "a", DefaultValue: 2}},
+			want: []*Change{{Kind: ChangeDefault, Key: "a", To: 2}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diffDocs(tt.from, tt.to); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffDocs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_defaultChangeBreaking(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		to   string
+		want bool
+	}{
+		{name: "no_default", from: `{}`, to: `{"value": 1}`, want: false},
+		{name: "changed", from: `{"value": 1}`, to: `{"value": 2}`, want: true},
+		{name: "type_changed", from: `{"value": {"a": 1}}`, to: `{"value": "a"}`, want: true},
+		{name: "key_added", from: `{"value": {"a": 1}}`, to: `{"value": {"a": 1, "b": 2}}`, want: false},
+		{name: "nested_key_added", from: `{"value": {"a": {"b": 1}}}`, to: `{"value": {"a": {"b": 1, "c": 2}}}`, want: false},
+		{name: "key_removed", from: `{"value": {"a": 1, "b": 2}}`, to: `{"value": {"a": 1}}`, want: true},
+		{name: "key_changed", from: `{"value": {"a": 1}}`, to: `{"value": {"a": 2}}`, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := defaultChangeBreaking(parseJson(tt.from)["value"], parseJson(tt.to)["value"]); got != tt.want {
+				t.Errorf("defaultChangeBreaking() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_flattenChartDocs(t *testing.T) {
+	port := &ConfigDoc{Description: "port", Deprecated: "use service.port", ReplacedBy: "service.port"}
+	chartDocs := &ChartDocs{
+		Chart: &chart.Chart{Metadata: &chart.Metadata{Name: "app", Version: "1.0.0"}},
+		Docs:  map[string]*ConfigDoc{"replicas": {Description: "replicas"}},
+		Dependencies: []*ChartDocs{{
+			Chart:       &chart.Chart{Metadata: &chart.Metadata{Name: "postgres", Version: "1.0.0"}},
+			Declaration: &chart.Dependency{Name: "postgres", Alias: "db"},
+			Docs:        map[string]*ConfigDoc{"port": port, "service.port": {Description: "service port"}, "global.region": {Description: "region"}},
+		}},
+	}
+	want := map[string]*ConfigDoc{
+		"replicas":        {Description: "replicas"},
+		"db.port":         {Description: "port", Deprecated: "use service.port", ReplacedBy: "db.service.port"},
+		"db.service.port": {Description: "service port"},
+		"global.region":   {Description: "region"},
+	}
+	if got := flattenChartDocs(chartDocs, ""); !reflect.DeepEqual(got, want) {
+		t.Errorf("flattenChartDocs() = %v, want %v", got, want)
+	}
+	if port.ReplacedBy != "service.port" {
+		t.Errorf("flattenChartDocs() modified the docs of the dependency: %s", port.ReplacedBy)
+	}
+}
+
+func Test_withoutVerification(t *testing.T) {
+	flags := CommandFlags{VerifyExamples: true, VerifyExamplesRender: true, VerifyValues: true, VerifyDependencies: true, VerifyTemplates: true,
+		VerifyUsage: true, UsedIn: true, VerifyDeprecated: "values.yaml", ParseComments: true}
+	want := CommandFlags{ParseComments: true}
+	if got := withoutVerification(flags); !reflect.DeepEqual(got, want) {
+		t.Errorf("withoutVerification() = %+v, want %+v", got, want)
+	}
+}
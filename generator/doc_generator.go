@@ -3,38 +3,63 @@ package generator
 import (
 	"fmt"
 	"github.com/ghodss/yaml"
-	"github.com/golang/protobuf/ptypes/any"
 	"github.com/random-dwi/helm-doc/helm"
 	"github.com/random-dwi/helm-doc/output"
-	"k8s.io/helm/pkg/proto/hapi/chart"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
 	"regexp"
+	"sort"
 	"strings"
 )
 
 type CommandFlags struct {
-	Verbose            bool
-	VerifyExamples     bool
-	VerifyValues       bool
-	VerifyDependencies bool
-	Version            string
-	RepoURL            string
-	Username           string
-	Password           string
-	Keyring            string
-	CertFile           string
-	KeyFile            string
-	CaFile             string
-	Verify             bool
-	Devel              bool
+	Verbose              bool
+	VerifyExamples       bool
+	VerifyExamplesRender bool
+	VerifyValues         bool
+	VerifyDependencies   bool
+	VerifyTemplates      bool
+	VerifyUsage          bool
+	UsedIn               bool
+	Version              string
+	RepoURL              string
+	Username             string
+	Password             string
+	Keyring              string
+	CertFile             string
+	KeyFile              string
+	CaFile               string
+	Verify               bool
+	Devel                bool
+	Output               string
+	OutputFile           string
+	OutputDir            string
+	Inject               string
+	ParseComments        bool
+	VerifyDeprecated     string
+	MigrateValues        string
 }
 
 type ConfigDoc struct {
-	Description  string
-	DefaultValue interface{}
-	ExampleValue interface{}
+	Description string        `json:"description" yaml:"description"`
+	Type        string        `json:"type,omitempty" yaml:"type,omitempty"`
+	Required    bool          `json:"required,omitempty" yaml:"required,omitempty"`
+	Enum        []interface{} `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Pattern     string        `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	// Deprecated tells why a value should not be used anymore, ReplacedBy is the key to use instead
+	Deprecated   string      `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+	ReplacedBy   string      `json:"replacedBy,omitempty" yaml:"replacedBy,omitempty"`
+	DefaultValue interface{} `json:"default,omitempty" yaml:"default,omitempty"`
+	ExampleValue interface{} `json:"example,omitempty" yaml:"example,omitempty"`
+	// Templates and Kinds are the templates and kinds of resources using the value, if requested
+	Templates []string `json:"templates,omitempty" yaml:"templates,omitempty"`
+	Kinds     []string `json:"kinds,omitempty" yaml:"kinds,omitempty"`
+	// Charts are the charts reading a global value
+	Charts []string `json:"charts,omitempty" yaml:"charts,omitempty"`
 }
 
-func GenerateDocs(c *chart.Chart, ignoredPrefixes []string, parentCharts map[*chart.Chart]*chart.Chart, flags CommandFlags) (map[string]*ConfigDoc, error) {
+// GenerateDocs generates the docs of a single chart, problems which do not fail the docs are collected into warnings
+func GenerateDocs(c *chart.Chart, ignoredPrefixes []string, tree *ChartTree, flags CommandFlags, warnings *Errors) (map[string]*ConfigDoc, error) {
 
 	var allValues = make(map[string]map[string]interface{})
 	var valueSource []string
@@ -45,12 +70,9 @@ func GenerateDocs(c *chart.Chart, ignoredPrefixes []string, parentCharts map[*ch
 	for currentChart != nil {
 		valueSource = append(valueSource, currentChart.Metadata.Name)
 
-		var rawValues []byte
-		if currentChart.Values == nil {
+		rawValues := findRawValues(currentChart)
+		if rawValues == nil {
 			output.Debugf("chart has no values.yaml")
-			rawValues = []byte("")
-		} else {
-			rawValues = []byte(currentChart.Values.Raw)
 		}
 
 		values, err := parseYaml(rawValues)
@@ -62,16 +84,16 @@ func GenerateDocs(c *chart.Chart, ignoredPrefixes []string, parentCharts map[*ch
 		if currentPrefix != "" {
 			val := findValueForKeyAndGlobal(currentPrefix, values, values["global"])
 			values, _ = val.(map[string]interface{})
-			currentPrefix = currentChart.Metadata.Name + "." + currentPrefix
+			currentPrefix = tree.ValuesKey(currentChart) + "." + currentPrefix
 		} else {
-			currentPrefix = currentChart.Metadata.Name
+			currentPrefix = tree.ValuesKey(currentChart)
 		}
 
 		allValues[currentChart.Metadata.Name] = values
-		currentChart = parentCharts[currentChart]
+		currentChart = tree.Parents[currentChart]
 	}
 
-	definitions, err := findAndParseYaml(c.Files, "definitions.yaml")
+	definitions, err := findDefinitions(c, flags)
 
 	if err != nil {
 		return nil, fmt.Errorf("unable to read definitions for %s:%s: %v", c.Metadata.Name, c.Metadata.Version, err)
@@ -83,16 +105,58 @@ func GenerateDocs(c *chart.Chart, ignoredPrefixes []string, parentCharts map[*ch
 		if flags.VerifyExamples {
 			return nil, fmt.Errorf("unable to read examples for %s:%s: %v", c.Metadata.Name, c.Metadata.Version, err)
 		} else {
-			output.Warnf("unable to read examples for %s:%s: %v", c.Metadata.Name, c.Metadata.Version, err)
+			*warnings = append(*warnings, fmt.Errorf("unable to read examples for %s:%s: %v", c.Metadata.Name, c.Metadata.Version, err))
 		}
 	}
 
-	return generate(definitions, allValues, valueSource, examples, ignoredPrefixes, flags), nil
+	var references *templateReferences
+
+	if flags.VerifyTemplates || flags.VerifyUsage || flags.UsedIn {
+		references, err = findTemplateReferences(c)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read templates for %s:%s: %v", c.Metadata.Name, c.Metadata.Version, err)
+		}
+		if parent := tree.Parents[c]; parent != nil {
+			for _, key := range conditionReferences(tree.Declarations[c]) {
+				references.addFile(key, parent.Metadata.Name+"/Chart.yaml")
+			}
+		}
+	}
+
+	chartName := fmt.Sprintf("%s:%s", c.Metadata.Name, c.Metadata.Version)
+
+	imported, err := importedValues(c, allValues[c.Metadata.Name], warnings)
+	if err != nil {
+		return nil, err
+	}
+
+	var renderProblems []string
+
+	// rendered before generating, as generating removes the values of dependencies
+	if flags.VerifyExamplesRender && examples != nil {
+		renderProblems = validateExampleRendering(c, allValues, valueSource, examples)
+	}
+
+	docs, err := generate(chartName, definitions, allValues, valueSource, imported, examples, references, ignoredPrefixes, flags)
+
+	if len(renderProblems) > 0 && docs != nil {
+		errs, _ := err.(Errors)
+		err = append(errs, &ValidationError{Chart: chartName, Rule: RuleExampleRender, Message: "examples failing to render detected", File: "examples.yaml", Keys: renderProblems})
+	}
+
+	return docs, err
 }
 
-func generate(definitions map[string]interface{}, allValues map[string]map[string]interface{}, valueSource []string, examples map[string]interface{}, ignoredPrefixes []string, flags CommandFlags) map[string]*ConfigDoc {
+// generate returns the docs even if validation errors are detected, so callers can decide how to handle them
+func generate(chartName string, definitions map[string]interface{}, allValues map[string]map[string]interface{}, valueSource []string, imported map[string]interface{}, examples map[string]interface{}, references *templateReferences, ignoredPrefixes []string, flags CommandFlags) (map[string]*ConfigDoc, error) {
 
-	docs := convertToConfigDocs("", definitions)
+	var errs Errors
+
+	docs, err := convertToConfigDocs("", definitions)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid definitions for %s: %v", chartName, err)
+	}
 
 	if len(ignoredPrefixes) > 0 {
 		for _, source := range valueSource {
@@ -106,22 +170,72 @@ func generate(definitions map[string]interface{}, allValues map[string]map[strin
 
 	if flags.VerifyValues {
 		missingKeys := validateDefaultValues("", definitions, allValues[valueSource[0]])
+		sort.Strings(missingKeys)
 		if len(missingKeys) > 0 {
-			var prefix = "\n\t"
-			output.Failf("undocumented values detected: %s%s", prefix, strings.Join(missingKeys, prefix))
+			errs = append(errs, &ValidationError{Chart: chartName, Rule: RuleUndocumentedValue, Message: "undocumented values detected", File: chartutil.ValuesfileName, Keys: missingKeys})
 		}
 	}
 
-	docs = insertDefaultValues(docs, allValues, valueSource)
+	docs, err = insertDefaultValues(docs, allValues, valueSource)
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to read default values for %s: %v", chartName, err)
+	}
+
+	docs, err = insertImportedValues(docs, imported)
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to read imported values for %s: %v", chartName, err)
+	}
 
 	if examples != nil {
-		docs = insertExampleValues(docs, examples, flags)
+		var missingExamples []string
+		docs, missingExamples, err = insertExampleValues(docs, examples, flags)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read examples for %s: %v", chartName, err)
+		}
+		if len(missingExamples) > 0 {
+			errs = append(errs, &ValidationError{Chart: chartName, Rule: RuleMissingExample, Message: "when --verify-examples is true an example needs to be provided for every config without default", File: "examples.yaml", Keys: missingExamples})
+		}
+	}
+
+	if flags.VerifyValues {
+		typeErrors := validateTypes(docs)
+		if len(typeErrors) > 0 {
+			errs = append(errs, &ValidationError{Chart: chartName, Rule: RuleTypeMismatch, Message: "values not matching their definition detected", File: chartutil.ValuesfileName, Keys: typeErrors})
+		}
+		unknownReplacements := findUnknownReplacements(docs)
+		if len(unknownReplacements) > 0 {
+			errs = append(errs, &ValidationError{Chart: chartName, Rule: RuleUnknownReplacement, Message: "deprecated values replaced by undocumented values detected", File: "definitions.yaml", Keys: unknownReplacements})
+		}
+	}
+
+	if flags.VerifyTemplates {
+		var values []map[string]interface{}
+		for _, source := range valueSource {
+			values = append(values, allValues[source])
+		}
+		undocumented := findUndocumentedReferences(references.files, docs, values, ignoredPrefixes)
+		if len(undocumented) > 0 {
+			errs = append(errs, &ValidationError{Chart: chartName, Rule: RuleUndocumentedTemplateValue, Message: "values used in templates without documentation or default detected", File: chartutil.ValuesfileName, Keys: undocumented, Files: references.files})
+		}
+	}
+
+	if flags.UsedIn {
+		insertUsage(docs, references)
 	}
 
-	return docs
+	if flags.VerifyUsage {
+		unused := findUnusedValues(references.files, docs, allValues[valueSource[0]])
+		if len(unused) > 0 {
+			errs = append(errs, &ValidationError{Chart: chartName, Rule: RuleUnusedValue, Message: "values not used by any template detected", File: chartutil.ValuesfileName, Keys: unused})
+		}
+	}
+
+	return docs, errs.ErrorOrNil()
 }
 
-func convertToConfigDocs(parentKey string, definitions map[string]interface{}) map[string]*ConfigDoc {
+func convertToConfigDocs(parentKey string, definitions map[string]interface{}) (map[string]*ConfigDoc, error) {
 
 	var descriptions = map[string]*ConfigDoc{}
 
@@ -133,6 +247,15 @@ func convertToConfigDocs(parentKey string, definitions map[string]interface{}) m
 			globalKey = parentKey + "." + key
 		}
 
+		if structuredDefinition, isStructured := asStructuredDefinition(value); isStructured {
+			configDoc, err := parseStructuredDefinition(globalKey, structuredDefinition)
+			if err != nil {
+				return nil, err
+			}
+			descriptions[globalKey] = configDoc
+			continue
+		}
+
 		defMap, isMap := value.(map[string]interface{})
 		if !isMap {
 			description, isString := value.(string)
@@ -141,27 +264,36 @@ func convertToConfigDocs(parentKey string, definitions map[string]interface{}) m
 			} else {
 				defArray, isArray := value.([]interface{})
 				if isArray {
-					if len(defArray) == 1 {
-						subDescriptions := convertToConfigDocs(globalKey+"[]", defArray[0].(map[string]interface{}))
-						for k, v := range subDescriptions {
-							descriptions[k] = v
-						}
-					} else {
-						output.Failf("definition can only be array with length 1: %s (value: %v)", globalKey, value)
+					if len(defArray) != 1 {
+						return nil, fmt.Errorf("definition can only be array with length 1: %s (value: %v)", globalKey, value)
+					}
+					rowMap, isRowMap := defArray[0].(map[string]interface{})
+					if !isRowMap {
+						return nil, fmt.Errorf("definition array has to contain a map: %s (value: %v)", globalKey, value)
+					}
+					subDescriptions, err := convertToConfigDocs(globalKey+"[]", rowMap)
+					if err != nil {
+						return nil, err
+					}
+					for k, v := range subDescriptions {
+						descriptions[k] = v
 					}
 				} else {
-					output.Failf("definition has to be either a map or a string: %s (value: %v)", globalKey, value)
+					return nil, fmt.Errorf("definition has to be either a map, a string or a structured definition: %s (value: %v)", globalKey, value)
 				}
 			}
 		} else {
-			subDescriptions := convertToConfigDocs(globalKey, defMap)
+			subDescriptions, err := convertToConfigDocs(globalKey, defMap)
+			if err != nil {
+				return nil, err
+			}
 			for k, v := range subDescriptions {
 				descriptions[k] = v
 			}
 		}
 	}
 
-	return descriptions
+	return descriptions, nil
 }
 
 func validateDefaultValues(parentKey string, definitions map[string]interface{}, values map[string]interface{}) []string {
@@ -207,7 +339,8 @@ func validateDefaultValues(parentKey string, definitions map[string]interface{},
 			continue
 		}
 
-		if findValueForKey(globalKey, definitions, true) == nil {
+		// looking up parent values in definitions never fails
+		if definition, _ := findValueForKey(globalKey, definitions, true); definition == nil {
 			missingKeys = append(missingKeys, globalKey)
 			continue
 		}
@@ -216,17 +349,21 @@ func validateDefaultValues(parentKey string, definitions map[string]interface{},
 	return missingKeys
 }
 
-func insertDefaultValues(docs map[string]*ConfigDoc, allValues map[string]map[string]interface{}, valueSource []string) map[string]*ConfigDoc {
+func insertDefaultValues(docs map[string]*ConfigDoc, allValues map[string]map[string]interface{}, valueSource []string) (map[string]*ConfigDoc, error) {
 
 	for globalKey, configDoc := range docs {
 		var defaultValue interface{} = nil
 		for _, source := range valueSource {
-			defaultValue = mergeValues(findValueForKey(globalKey, allValues[source], false), defaultValue)
+			value, err := findValueForKey(globalKey, allValues[source], false)
+			if err != nil {
+				return nil, err
+			}
+			defaultValue = mergeValues(value, defaultValue)
 		}
 		configDoc.DefaultValue = defaultValue
 	}
 
-	return docs
+	return docs, nil
 }
 
 func mergeValues(defaultParent interface{}, defaultChild interface{}) interface{} {
@@ -244,23 +381,25 @@ func mergeValues(defaultParent interface{}, defaultChild interface{}) interface{
 	}
 }
 
-func insertExampleValues(docs map[string]*ConfigDoc, examples map[string]interface{}, flags CommandFlags) map[string]*ConfigDoc {
+// insertExampleValues returns the keys without default and example, if examples are verified
+func insertExampleValues(docs map[string]*ConfigDoc, examples map[string]interface{}, flags CommandFlags) (map[string]*ConfigDoc, []string, error) {
 
 	var missingExamples []string
 
 	for globalKey, configDoc := range docs {
-		configDoc.ExampleValue = findValueForKey(globalKey, examples, false)
+		exampleValue, err := findValueForKey(globalKey, examples, false)
+		if err != nil {
+			return nil, nil, err
+		}
+		configDoc.ExampleValue = exampleValue
 		if flags.VerifyExamples && configDoc.ExampleValue == nil && configDoc.DefaultValue == nil {
 			missingExamples = append(missingExamples, globalKey)
 		}
 	}
 
-	if missingExamples != nil {
-		var prefix = "\n\t"
-		output.Failf("when --verify-examples is true an example needs to be provided for every config without default: %s%s", prefix, strings.Join(missingExamples, prefix))
-	}
+	sort.Strings(missingExamples)
 
-	return docs
+	return docs, missingExamples, nil
 }
 
 // find definition for a given key or a parent key
@@ -272,32 +411,33 @@ func findDefinitionForKeyOrParentKey(globalKey string, definitions map[string]in
 	for _, key := range keys {
 		baseKey, isArray := isArrayKey(key)
 		if _, exists := currentMap[baseKey]; exists {
+			if isDefinitionLeaf(currentMap[baseKey]) {
+				return true
+			}
 			if !isArray {
 				newMap, isMap := currentMap[baseKey].(map[string]interface{})
 				if isMap {
 					currentMap = newMap
 				} else {
-					_, isString := currentMap[baseKey].(string)
-					return isString
+					return false
 				}
 			} else {
 				// it is an array
 				newArray, isDefArray := currentMap[baseKey].([]interface{})
 				if isDefArray {
 					if len(newArray) != 1 {
-						output.Failf("only arrays with length 1 allowed in definition: %s", globalKey)
+						// invalid array definitions are reported by convertToConfigDocs
+						return false
 					} else {
 						newMap, isMap := newArray[0].(map[string]interface{})
 						if isMap {
 							currentMap = newMap
 						} else {
-							_, isString := currentMap[baseKey].(string)
-							return isString
+							return false
 						}
 					}
 				} else {
-					_, isString := currentMap[baseKey].(string)
-					return isString
+					return false
 				}
 			}
 		} else {
@@ -340,7 +480,7 @@ func findValueForKeyAndGlobal(globalKey string, values map[string]interface{}, g
 
 // find value for a given key or nil if it does not exist
 // if `useParentValue` is true, instead of nil the parent value is returned if available
-func findValueForKey(globalKey string, values map[string]interface{}, useParentValue bool) interface{} {
+func findValueForKey(globalKey string, values map[string]interface{}, useParentValue bool) (interface{}, error) {
 
 	keys := strings.Split(globalKey, ".")
 
@@ -354,24 +494,27 @@ func findValueForKey(globalKey string, values map[string]interface{}, useParentV
 			subKey := strings.TrimPrefix(strings.TrimPrefix(globalKey, joinedKey), ".")
 
 			if subKey == "" {
-				return subValues
+				return subValues, nil
 			} else if !isArray {
 				newMap, isMap := subValues.(map[string]interface{})
-				if isMap {
+				if isMap && useParentValue && isStructuredDefinition(newMap) {
+					// a structured definition documents all of its children
+					return newMap, nil
+				} else if isMap {
 					return findValueForKey(subKey, newMap, useParentValue)
 				} else {
 					// we cannot go deeper but we have not found the full key
 					if useParentValue {
-						return subValues
+						return subValues, nil
 					} else {
-						return nil
+						return nil, nil
 					}
 				}
 			} else {
 				subArray, isArray := subValues.([]interface{})
 				if isArray {
 					if len(subArray) == 0 {
-						return nil
+						return nil, nil
 					} else {
 						newMap, isMap := subArray[0].(map[string]interface{})
 						if isMap {
@@ -379,24 +522,24 @@ func findValueForKey(globalKey string, values map[string]interface{}, useParentV
 						} else {
 							// we cannot go deeper but we have not found the full key
 							if useParentValue {
-								return subArray[0]
+								return subArray[0], nil
 							} else {
-								return nil
+								return nil, nil
 							}
 						}
 					}
 				} else {
 					if useParentValue {
-						return subValues
+						return subValues, nil
 					} else {
-						output.Failf("expected array in examples: %s", globalKey)
+						return nil, fmt.Errorf("expected array: %s", globalKey)
 					}
 				}
 			}
 		}
 	}
 
-	return nil
+	return nil, nil
 }
 
 func isArrayKey(key string) (string, bool) {
@@ -418,15 +561,45 @@ func parseYaml(bytes []byte) (map[string]interface{}, error) {
 	return valueMap, nil
 }
 
-func findAndParseYaml(files []*any.Any, filename string) (map[string]interface{}, error) {
-	for _, file := range files {
-		if file.TypeUrl == filename {
-			return parseYaml(file.Value)
+// findDefinitions reads definitions.yaml merged with the definitions from comments in values.yaml.
+// definitions.yaml wins on conflicts.
+func findDefinitions(c *chart.Chart, flags CommandFlags) (map[string]interface{}, error) {
+
+	definitions, err := findAndParseYaml(c.Files, "definitions.yaml")
+
+	if err != nil {
+		// definitions.yaml is optional if values.yaml is documented by comments
+		commentDefinitions, commentErr := withCommentDefinitions(c, map[string]interface{}{}, flags)
+		if commentErr != nil || len(commentDefinitions) == 0 {
+			return nil, err
 		}
+		return commentDefinitions, nil
+	}
+
+	return withCommentDefinitions(c, definitions, flags)
+}
+
+func findAndParseYaml(files []*chart.File, filename string) (map[string]interface{}, error) {
+	if file := findFile(files, filename); file != nil {
+		return parseYaml(file)
 	}
 	return nil, fmt.Errorf("required file not found in chart: %s", filename)
 }
 
+func findFile(files []*chart.File, filename string) []byte {
+	for _, file := range files {
+		if file.Name == filename {
+			return file.Data
+		}
+	}
+	return nil
+}
+
+// findRawValues returns the unparsed values.yaml of a chart, as the parsed `chart.Values` lose comments
+func findRawValues(c *chart.Chart) []byte {
+	return findFile(c.Raw, chartutil.ValuesfileName)
+}
+
 func containsString(list []string, element string) bool {
 	for _, it := range list {
 		if it == element {
@@ -31,6 +31,9 @@ func Test_validateDefaultValues(t *testing.T) {
 		{name: "string_array", args: args{parentKey: "", definitions: `{"array": "array doc"}`, values: `{"array": ["child1", "child2"]}`}, want: nil},
 		{name: "string_array_missing", args: args{parentKey: "", definitions: `{}`, values: `{"array": ["child1", "child2"]}`}, want: []string{"array"}},
 		{name: "int_array_missing", args: args{parentKey: "", definitions: `{}`, values: `{"array": [1,2,3]}`}, want: []string{"array"}},
+		{name: "structured_definition", args: args{parentKey: "", definitions: `{"key": {"description": "docs", "type": "integer"}}`, values: `{"key": 123}`}, want: nil},
+		{name: "structured_definition_parent", args: args{parentKey: "", definitions: `{"parent": {"description": "docs", "type": "object"}}`, values: `{"parent": {"child": 123}}`}, want: nil},
+		{name: "structured_definition_array", args: args{parentKey: "", definitions: `{"array": {"description": "docs", "type": "array"}}`, values: `{"array": ["child1", "child2"]}`}, want: nil},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -41,6 +44,103 @@ func Test_validateDefaultValues(t *testing.T) {
 	}
 }
 
+func Test_generate_collectsErrors(t *testing.T) {
+	definitions := parseJson(`{"documented": "docs", "password": {"description": "docs", "type": "string"}}`)
+	allValues := map[string]map[string]interface{}{"chart": parseJson(`{"documented": 1, "password": null, "undocumented": 2, "zone": {"name": "a"}, "another": 3, "affinity": {}}`)}
+	examples := parseJson(`{}`)
+	flags := CommandFlags{VerifyValues: true, VerifyExamples: true}
+
+	docs, err := generate("chart:1.0.0", definitions, allValues, []string{"chart"}, nil, examples, nil, nil, flags)
+
+	if len(docs) != 2 {
+		t.Errorf("generate() docs = %v, want docs for all definitions", docs)
+	}
+
+	want := Errors{
+		&ValidationError{Chart: "chart:1.0.0", Rule: RuleUndocumentedValue, Message: "undocumented values detected", File: "values.yaml", Keys: []string{"affinity", "another", "undocumented", "zone.name"}},
+		&ValidationError{Chart: "chart:1.0.0", Rule: RuleMissingExample, Message: "when --verify-examples is true an example needs to be provided for every config without default", File: "examples.yaml", Keys: []string{"password"}},
+	}
+	if !reflect.DeepEqual(err, want) {
+		t.Errorf("generate() error = %v, want %v", err, want)
+	}
+}
+
+func Test_convertToConfigDocs(t *testing.T) {
+	tests := []struct {
+		name        string
+		definitions string
+		want        map[string]*ConfigDoc
+		wantErr     bool
+	}{
+		{name: "string", definitions: `{"key": "docs"}`, want: map[string]*ConfigDoc{"key": {Description: "docs"}}},
+		{name: "nested", definitions: `{"parent": {"child": "docs"}}`, want: map[string]*ConfigDoc{"parent.child": {Description: "docs"}}},
+		{name: "array", definitions: `{"parent": [{"child": "docs"}]}`, want: map[string]*ConfigDoc{"parent[].child": {Description: "docs"}}},
+		{name: "structured", definitions: `{"key": {"description": "docs", "type": "string", "required": true, "enum": ["a", "b"], "pattern": "^[ab]$"}}`,
+			want: map[string]*ConfigDoc{"key": {Description: "docs", Type: "string", Required: true, Enum: []interface{}{"a", "b"}, Pattern: "^[ab]$"}}},
+		{name: "deprecated", definitions: `{"key": {"description": "docs", "deprecated": "use other instead", "replacedBy": "other"}}`,
+			want: map[string]*ConfigDoc{"key": {Description: "docs", Deprecated: "use other instead", ReplacedBy: "other"}}},
+		{name: "map_with_description_child", definitions: `{"parent": {"description": "docs", "child": "child docs"}}`,
+			want: map[string]*ConfigDoc{"parent.description": {Description: "docs"}, "parent.child": {Description: "child docs"}}},
+		{name: "map_with_description_and_type_child", definitions: `{"service": {"description": "docs", "type": "type of the service"}}`,
+			want: map[string]*ConfigDoc{"service.description": {Description: "docs"}, "service.type": {Description: "type of the service"}}},
+		{name: "map_with_required_child", definitions: `{"tls": {"description": "docs", "required": "set when TLS is on"}}`,
+			want: map[string]*ConfigDoc{"tls.description": {Description: "docs"}, "tls.required": {Description: "set when TLS is on"}}},
+		{name: "map_with_enum_child", definitions: `{"level": {"description": "docs", "enum": "allowed levels"}}`,
+			want: map[string]*ConfigDoc{"level.description": {Description: "docs"}, "level.enum": {Description: "allowed levels"}}},
+		{name: "map_with_pattern_child", definitions: `{"log": {"description": "docs", "pattern": "log format"}}`,
+			want: map[string]*ConfigDoc{"log.description": {Description: "docs"}, "log.pattern": {Description: "log format"}}},
+		{name: "map_with_deprecated_child", definitions: `{"api": {"description": "docs", "deprecated": {"enabled": "serve the deprecated api"}}}`,
+			want: map[string]*ConfigDoc{"api.description": {Description: "docs"}, "api.deprecated.enabled": {Description: "serve the deprecated api"}}},
+		{name: "map_with_replaced_by_child", definitions: `{"api": {"description": "docs", "replacedBy": {"version": "version of the new api"}}}`,
+			want: map[string]*ConfigDoc{"api.description": {Description: "docs"}, "api.replacedBy.version": {Description: "version of the new api"}}},
+		{name: "pattern_with_type", definitions: `{"key": {"description": "docs", "type": "string", "pattern": "^[a-z]+$"}}`,
+			want: map[string]*ConfigDoc{"key": {Description: "docs", Type: "string", Pattern: "^[a-z]+$"}}},
+		{name: "invalid_array", definitions: `{"parent": [{"child1": "docs"}, {"child2": "docs"}]}`, wantErr: true},
+		{name: "empty_array", definitions: `{"parent": []}`, wantErr: true},
+		{name: "array_of_strings", definitions: `{"parent": ["docs"]}`, wantErr: true},
+		{name: "invalid_leaf", definitions: `{"key": 123}`, wantErr: true},
+		{name: "invalid_deprecated", definitions: `{"key": {"description": "docs", "deprecated": true}}`, wantErr: true},
+		{name: "invalid_type", definitions: `{"key": {"description": "docs", "type": 5}}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := convertToConfigDocs("", parseJson(tt.definitions))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("convertToConfigDocs() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("convertToConfigDocs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_validateTypes(t *testing.T) {
+	tests := []struct {
+		name string
+		docs map[string]*ConfigDoc
+		want []string
+	}{
+		{name: "untyped", docs: map[string]*ConfigDoc{"key": {DefaultValue: "value"}}, want: nil},
+		{name: "no_values", docs: map[string]*ConfigDoc{"key": {Type: "integer"}}, want: nil},
+		{name: "integer", docs: map[string]*ConfigDoc{"key": {Type: "integer", DefaultValue: 1.0, ExampleValue: 2.0}}, want: nil},
+		{name: "integer_mismatch", docs: map[string]*ConfigDoc{"key": {Type: "integer", DefaultValue: 1.5, ExampleValue: "2"}},
+			want: []string{"key: default 1.5 is not of type integer", "key: example 2 is not of type integer"}},
+		{name: "enum_mismatch", docs: map[string]*ConfigDoc{"key": {Enum: []interface{}{"a", "b"}, DefaultValue: "c"}},
+			want: []string{"key: default c is not one of [a b]"}},
+		{name: "pattern_mismatch", docs: map[string]*ConfigDoc{"key": {Type: "string", Pattern: "^[0-9]+m$", DefaultValue: "100m", ExampleValue: "1cpu"}},
+			want: []string{"key: example 1cpu does not match pattern ^[0-9]+m$"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validateTypes(tt.docs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("validateTypes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func Test_findValueForKey(t *testing.T) {
 	type args struct {
 		globalKey      string
@@ -48,9 +148,10 @@ func Test_findValueForKey(t *testing.T) {
 		useParentValue bool
 	}
 	tests := []struct {
-		name string
-		args args
-		want interface{}
+		name    string
+		args    args
+		want    interface{}
+		wantErr bool
 	}{
 		{name: "not_found", args: args{globalKey: "global.secret", values: "{}"}, want: nil},
 		{name: "find_int", args: args{globalKey: "global.secret", values: `{"global": {"secret": 123}}`}, want: 123.0},
@@ -60,10 +161,16 @@ func Test_findValueForKey(t *testing.T) {
 		{name: "find_inline_complex2", args: args{globalKey: "global.secret.value", values: `{"global": {"secret.value": "expected"}}`}, want: "expected"},
 		{name: "find_array", args: args{globalKey: "array[].secret.value", values: `{"array": [{"secret.value": "expected"}]}`}, want: "expected"},
 		{name: "find_array_parent", args: args{globalKey: "array[].child", values: `{"array": "docs"}`, useParentValue: true}, want: "docs"},
+		{name: "expected_array", args: args{globalKey: "array[].child", values: `{"array": "docs"}`}, wantErr: true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := findValueForKey(tt.args.globalKey, parseJson(tt.args.values), tt.args.useParentValue); !reflect.DeepEqual(got, tt.want) {
+			got, err := findValueForKey(tt.args.globalKey, parseJson(tt.args.values), tt.args.useParentValue)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("findValueForKey() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("findValueForKey() = %v, want %v", got, tt.want)
 			}
 		})
@@ -104,3 +211,18 @@ func parseJson(value string) map[string]interface{} {
 
 	return valueMap
 }
+
+func Test_findUnknownReplacements(t *testing.T) {
+	docs := map[string]*ConfigDoc{
+		"image.repository":     {ReplacedBy: "image.repo"},
+		"image.repo":           {},
+		"tag":                  {ReplacedBy: "image"},
+		"name":                 {ReplacedBy: "fullname"},
+		"hosts":                {ReplacedBy: "ingress.hosts"},
+		"ingress.hosts[].name": {},
+	}
+	want := []string{"name: replaced by fullname"}
+	if got := findUnknownReplacements(docs); !reflect.DeepEqual(got, want) {
+		t.Errorf("findUnknownReplacements() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,61 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// rules of the validations, used to identify findings in lint reports
+const (
+	RuleUndocumentedValue         = "undocumented-value"
+	RuleMissingExample            = "missing-example"
+	RuleTypeMismatch              = "type-mismatch"
+	RuleUndocumentedTemplateValue = "undocumented-template-value"
+	RuleUnusedValue               = "unused-value"
+	RuleExampleRender             = "example-render"
+	RuleMissingDependency         = "missing-dependency"
+	RuleUnknownReplacement        = "unknown-replacement"
+)
+
+// ValidationError reports the keys of a chart that failed a validation.
+type ValidationError struct {
+	Chart   string
+	Rule    string
+	Message string
+	// File is the file of the chart to fix, e.g. values.yaml
+	File string
+	Keys []string
+	// Files are the files a key is found in if they differ from File, e.g. templates
+	Files map[string][]string
+}
+
+func (e *ValidationError) Error() string {
+	var prefix = "\n\t"
+	var keys []string
+	for _, key := range e.Keys {
+		if files := e.Files[key]; len(files) > 0 {
+			key = fmt.Sprintf("%s (%s)", key, strings.Join(files, ", "))
+		}
+		keys = append(keys, key)
+	}
+	return fmt.Sprintf("%s in %s: %s%s", e.Message, e.Chart, prefix, strings.Join(keys, prefix))
+}
+
+// Errors collects the problems of a chart and its dependencies, so all of them can be reported at once.
+type Errors []error
+
+func (e Errors) Error() string {
+	var messages []string
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+// ErrorOrNil returns nil if no errors were collected.
+func (e Errors) ErrorOrNil() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
@@ -0,0 +1,133 @@
+package generator
+
+import (
+	"fmt"
+	"helm.sh/helm/v3/pkg/chart"
+	"sort"
+	"strings"
+)
+
+// Explanation is the documentation of a single key of a chart or one of its dependencies
+type Explanation struct {
+	Key string `json:"key" yaml:"key"`
+	// Charts is the path in the dependency tree to the chart owning the key, starting with the root chart
+	Charts []string `json:"charts" yaml:"charts"`
+	// Doc is nil for keys only documented by their children, e.g. `image` if `image.tag` is documented
+	Doc *ConfigDoc `json:"doc,omitempty" yaml:"doc,omitempty"`
+	// Children maps the direct children of the key to their description, which is empty if they are not documented themselves
+	Children map[string]string `json:"children,omitempty" yaml:"children,omitempty"`
+}
+
+// ownedDocs are the docs of a chart with the path in the tree to the chart and the key of its values in the root chart
+type ownedDocs struct {
+	docs   *ChartDocs
+	charts []string
+	prefix string
+}
+
+func walkOwnedDocs(chartDocs *ChartDocs, charts []string, prefix string) []ownedDocs {
+
+	charts = append(append([]string{}, charts...), fmt.Sprintf("%s:%s", chartDocs.Chart.Metadata.Name, chartDocs.Chart.Metadata.Version))
+	owned := []ownedDocs{{docs: chartDocs, charts: charts, prefix: prefix}}
+
+	for _, dependency := range chartDocs.Dependencies {
+		dependencyPrefix := PrefixKey(prefix, valuesKey(dependency.Chart, dependency.Declaration))
+		owned = append(owned, walkOwnedDocs(dependency, charts, dependencyPrefix)...)
+	}
+
+	return owned
+}
+
+// ExplainKey returns the documentation of a key as seen by the chart, e.g. `db.user.name` or `hosts[].name`.
+// Global keys are explained with the global values of the whole tree. The default is the merged default of the docs.
+func ExplainKey(c *chart.Chart, key string, flags CommandFlags) (*Explanation, error) {
+
+	// problems of the chart do not matter for looking up a single key
+	chartDocs, err := GenerateChartDocs(c, withoutVerification(flags))
+	if err != nil {
+		return nil, err
+	}
+
+	return explainKey(chartDocs, key)
+}
+
+func explainKey(chartDocs *ChartDocs, key string) (*Explanation, error) {
+
+	explanation := &Explanation{Key: key, Children: map[string]string{}}
+	tokens := keyTokens(key)
+
+	var known []string
+	// explain collects a documented key, a key documented by its children only is owned by the chart of the first child
+	explain := func(owner ownedDocs, fullKey string, configDoc *ConfigDoc) {
+		known = append(known, fullKey)
+		if fullKey == key {
+			explanation.Charts, explanation.Doc = owner.charts, configDoc
+			return
+		}
+		child, isChild := childKey(tokens, keyTokens(fullKey))
+		if !isChild {
+			return
+		}
+		if explanation.Charts == nil {
+			explanation.Charts = owner.charts
+		}
+		if child == fullKey {
+			explanation.Children[child] = configDoc.Description
+		} else if _, exists := explanation.Children[child]; !exists {
+			explanation.Children[child] = ""
+		}
+	}
+
+	owned := walkOwnedDocs(chartDocs, nil, "")
+	for _, chartOwned := range owned {
+		for docKey, configDoc := range chartOwned.docs.Docs {
+			if !strings.HasPrefix(docKey, globalPrefix) {
+				explain(chartOwned, PrefixKey(chartOwned.prefix, docKey), configDoc)
+			}
+		}
+	}
+	for docKey, configDoc := range chartDocs.Globals {
+		explain(owned[0], docKey, configDoc)
+	}
+
+	// the values of a dependency belong to the dependency, even if the parent documents some of them
+	for _, chartOwned := range owned[1:] {
+		if chartOwned.prefix == key && explanation.Doc == nil {
+			explanation.Charts = chartOwned.charts
+		}
+	}
+
+	if explanation.Charts == nil {
+		sort.Strings(known)
+		return nil, fmt.Errorf("key %s is not documented by %s or its dependencies", withSuggestion("", key, known), owned[0].charts[0])
+	}
+
+	return explanation, nil
+}
+
+// childKey returns the direct child of the key of parentTokens which is the key of tokens or one of its parents
+func childKey(parentTokens []string, tokens []string) (string, bool) {
+
+	if len(tokens) <= len(parentTokens) {
+		return "", false
+	}
+	for i := range parentTokens {
+		if parentTokens[i] != tokens[i] {
+			return "", false
+		}
+	}
+
+	key := ""
+	for i, token := range tokens[:len(parentTokens)+1] {
+		switch {
+		case token == "[]":
+			key += token
+		case i == 0:
+			key = token
+		default:
+			key += "." + token
+		}
+	}
+
+	return key, true
+}
@@ -0,0 +1,64 @@
+package generator
+
+import (
+	"helm.sh/helm/v3/pkg/chart"
+	"reflect"
+	"testing"
+)
+
+func Test_explainKey(t *testing.T) {
+	db := &ChartDocs{
+		Chart:       &chart.Chart{Metadata: &chart.Metadata{Name: "postgres", Version: "1.0.0"}},
+		Declaration: &chart.Dependency{Name: "postgres", Alias: "db"},
+		Docs: map[string]*ConfigDoc{
+			"user.name":     {Description: "db user", DefaultValue: "root"},
+			"global.region": {Description: "region of the database"},
+		},
+	}
+	chartDocs := &ChartDocs{
+		Chart: &chart.Chart{Metadata: &chart.Metadata{Name: "app", Version: "1.0.0"}},
+		Docs: map[string]*ConfigDoc{
+			"image.tag":    {Description: "image tag", DefaultValue: "stable"},
+			"hosts[].name": {Description: "host name"},
+			"hosts[].path": {Description: "host path"},
+		},
+		Dependencies: []*ChartDocs{db},
+		Globals:      map[string]*ConfigDoc{"global.region": {Description: "deployment region", Charts: []string{"app", "db"}}},
+	}
+	tests := []struct {
+		name    string
+		key     string
+		want    *Explanation
+		wantErr string
+	}{
+		{name: "documented", key: "image.tag",
+			want: &Explanation{Key: "image.tag", Charts: []string{"app:1.0.0"}, Doc: chartDocs.Docs["image.tag"], Children: map[string]string{}}},
+		{name: "parent", key: "image",
+			want: &Explanation{Key: "image", Charts: []string{"app:1.0.0"}, Children: map[string]string{"image.tag": "image tag"}}},
+		{name: "array", key: "hosts",
+			want: &Explanation{Key: "hosts", Charts: []string{"app:1.0.0"}, Children: map[string]string{"hosts[]": ""}}},
+		{name: "array_element", key: "hosts[]",
+			want: &Explanation{Key: "hosts[]", Charts: []string{"app:1.0.0"}, Children: map[string]string{"hosts[].name": "host name", "hosts[].path": "host path"}}},
+		{name: "dependency", key: "db",
+			want: &Explanation{Key: "db", Charts: []string{"app:1.0.0", "postgres:1.0.0"}, Children: map[string]string{"db.user": ""}}},
+		{name: "dependency_key", key: "db.user.name",
+			want: &Explanation{Key: "db.user.name", Charts: []string{"app:1.0.0", "postgres:1.0.0"}, Doc: db.Docs["user.name"], Children: map[string]string{}}},
+		{name: "global", key: "global.region",
+			want: &Explanation{Key: "global.region", Charts: []string{"app:1.0.0"}, Doc: chartDocs.Globals["global.region"], Children: map[string]string{}}},
+		{name: "unknown", key: "image.tga", wantErr: "key image.tga (did you mean image.tag?) is not documented by app:1.0.0 or its dependencies"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := explainKey(chartDocs, tt.key)
+			if err != nil {
+				if err.Error() != tt.wantErr {
+					t.Fatalf("explainKey() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("explainKey() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
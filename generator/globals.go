@@ -0,0 +1,120 @@
+package generator
+
+import (
+	"fmt"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// RuleGlobalConflict is used for global values described differently by several charts
+const RuleGlobalConflict = "global-conflict"
+
+const globalPrefix = "global."
+
+// chartPath is a chart of the tree with the path of its directory relative to the root chart, e.g. `charts/db/`
+type chartPath struct {
+	docs *ChartDocs
+	path string
+}
+
+// collectGlobals merges the documented global values of all charts of the tree and adds the charts whose templates read them.
+// The root chart wins if charts describe a global value differently, the conflicts are collected like problems of dependencies.
+func collectGlobals(root *ChartDocs, flags CommandFlags, errs *Errors, warnings *Errors) map[string]*ConfigDoc {
+
+	globals := map[string]*ConfigDoc{}
+	describedBy := map[string][]string{}
+	conflicts := map[string]bool{}
+
+	charts := walkChartDocs(root, "")
+
+	for _, current := range charts {
+		for key, configDoc := range current.docs.Docs {
+			if !strings.HasPrefix(key, globalPrefix) {
+				continue
+			}
+			describedBy[key] = append(describedBy[key], current.path+definitionsFileName(current.docs.Chart))
+			existing, exists := globals[key]
+			if !exists {
+				copied := *configDoc
+				copied.Templates, copied.Kinds = nil, nil
+				globals[key] = &copied
+				continue
+			}
+			if describedDifferently(existing, configDoc) {
+				conflicts[key] = true
+			}
+			if existing.DefaultValue == nil {
+				existing.DefaultValue = configDoc.DefaultValue
+			}
+		}
+	}
+
+	for _, current := range charts {
+		references, err := findTemplateReferences(current.docs.Chart)
+		if err != nil {
+			// invalid templates are reported by the docs of the chart
+			continue
+		}
+		name := valuesKey(current.docs.Chart, current.docs.Declaration)
+		for key, configDoc := range globals {
+			for referencedKey := range references.files {
+				if tokensOverlap(keyTokens(referencedKey), keyTokens(key)) && !containsString(configDoc.Charts, name) {
+					configDoc.Charts = append(configDoc.Charts, name)
+				}
+			}
+		}
+	}
+
+	if len(conflicts) > 0 {
+		conflict := &ValidationError{
+			Chart:   fmt.Sprintf("%s:%s", root.Chart.Metadata.Name, root.Chart.Metadata.Version),
+			Rule:    RuleGlobalConflict,
+			Message: "global values described differently by several charts",
+			File:    definitionsFileName(root.Chart),
+			Files:   map[string][]string{},
+		}
+		for key := range conflicts {
+			conflict.Keys = append(conflict.Keys, key)
+			conflict.Files[key] = describedBy[key]
+		}
+		sort.Strings(conflict.Keys)
+		if flags.VerifyDependencies {
+			*errs = append(*errs, conflict)
+		} else {
+			*warnings = append(*warnings, conflict)
+		}
+	}
+
+	return globals
+}
+
+// walkChartDocs returns the charts of the tree depth first, starting with the root
+func walkChartDocs(chartDocs *ChartDocs, path string) []chartPath {
+
+	charts := []chartPath{{docs: chartDocs, path: path}}
+
+	for _, dependency := range chartDocs.Dependencies {
+		charts = append(charts, walkChartDocs(dependency, path+"charts/"+dependency.Chart.Metadata.Name+"/")...)
+	}
+
+	return charts
+}
+
+// definitionsFileName returns the file documenting the values of a chart, which is values.yaml if there are no definitions
+func definitionsFileName(c *chart.Chart) string {
+	if findFile(c.Files, "definitions.yaml") != nil {
+		return "definitions.yaml"
+	}
+	return chartutil.ValuesfileName
+}
+
+func describedDifferently(configDoc *ConfigDoc, other *ConfigDoc) bool {
+	return configDoc.Description != other.Description ||
+		configDoc.Type != other.Type ||
+		configDoc.Required != other.Required ||
+		!reflect.DeepEqual(configDoc.Enum, other.Enum) ||
+		configDoc.Pattern != other.Pattern
+}
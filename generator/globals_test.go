@@ -0,0 +1,61 @@
+package generator
+
+import (
+	"helm.sh/helm/v3/pkg/chart"
+	"reflect"
+	"testing"
+)
+
+func Test_collectGlobals(t *testing.T) {
+
+	newChart := func(name string, template string) *chart.Chart {
+		return &chart.Chart{
+			Metadata:  &chart.Metadata{Name: name, Version: "1.0.0"},
+			Templates: []*chart.File{{Name: "templates/test.yaml", Data: []byte(template)}},
+		}
+	}
+
+	root := &ChartDocs{
+		Chart: newChart("app", `{{ .Values.global.region }}`),
+		Docs: map[string]*ConfigDoc{
+			"global.region": {Description: "deployment region", DefaultValue: "eu"},
+			"replicas":      {Description: "number of replicas"},
+		},
+		Dependencies: []*ChartDocs{
+			{
+				Chart:       newChart("db", `{{ .Values.global.region }}{{ .Values.global.image.registry }}`),
+				Declaration: &chart.Dependency{Name: "db", Alias: "primary"},
+				Docs: map[string]*ConfigDoc{
+					"global.region": {Description: "region of the database", DefaultValue: "eu"},
+					"global.image":  {Description: "image settings", DefaultValue: map[string]interface{}{"registry": "docker.io"}, Templates: []string{"templates/test.yaml"}},
+				},
+			},
+			{
+				Chart: newChart("cache", `{{ .Values.global.image }}`),
+				Docs:  map[string]*ConfigDoc{"global.image": {Description: "image settings"}},
+			},
+		},
+	}
+
+	var errs, warnings Errors
+	globals := collectGlobals(root, CommandFlags{}, &errs, &warnings)
+
+	want := map[string]*ConfigDoc{
+		"global.region": {Description: "deployment region", DefaultValue: "eu", Charts: []string{"app", "primary"}},
+		"global.image":  {Description: "image settings", DefaultValue: map[string]interface{}{"registry": "docker.io"}, Charts: []string{"primary", "cache"}},
+	}
+	if !reflect.DeepEqual(globals, want) {
+		t.Errorf("collectGlobals() = %v, want %v", globals, want)
+	}
+
+	if len(errs) != 0 || len(warnings) != 1 {
+		t.Fatalf("collectGlobals() errs = %v, warnings = %v, want a single warning", errs, warnings)
+	}
+	conflict := warnings[0].(*ValidationError)
+	if conflict.Rule != RuleGlobalConflict || !reflect.DeepEqual(conflict.Keys, []string{"global.region"}) {
+		t.Errorf("collectGlobals() warning = %v, want conflict of global.region", conflict)
+	}
+	if want := []string{"values.yaml", "charts/db/values.yaml"}; !reflect.DeepEqual(conflict.Files["global.region"], want) {
+		t.Errorf("collectGlobals() files = %v, want %v", conflict.Files["global.region"], want)
+	}
+}
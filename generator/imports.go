@@ -0,0 +1,103 @@
+package generator
+
+import (
+	"fmt"
+	"github.com/random-dwi/helm-doc/helm"
+	"helm.sh/helm/v3/pkg/chart"
+	"strings"
+)
+
+// importedValues returns the values a chart imports from its dependencies with `import-values`.
+// A string imports the `exports` of that name of the dependency into the top level,
+// a map imports the `child` path of the values of the dependency to the `parent` path.
+// The values of the dependencies include those set by the chart. The first import wins on conflicts like in helm.
+// Imports of tables missing in the dependency are collected into warnings.
+func importedValues(c *chart.Chart, values map[string]interface{}, warnings *Errors) (map[string]interface{}, error) {
+
+	imported := map[string]interface{}{}
+
+	for _, dependency := range dependencyInstances(c) {
+		if dependency.declaration == nil || len(dependency.declaration.ImportValues) == 0 {
+			continue
+		}
+
+		key := valuesKey(dependency.chart, dependency.declaration)
+
+		dependencyValues, err := parseYaml(findRawValues(dependency.chart))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read values for %s:%s: %v", dependency.chart.Metadata.Name, dependency.chart.Metadata.Version, err)
+		}
+		if parentValues, isMap := values[key].(map[string]interface{}); isMap {
+			dependencyValues = helm.MergeValues(dependencyValues, copyValues(parentValues))
+		}
+
+		for _, importValue := range dependency.declaration.ImportValues {
+			var childPath, parentPath string
+			switch typed := importValue.(type) {
+			case string:
+				childPath, parentPath = "exports."+typed, ""
+			case map[string]interface{}:
+				childPath, parentPath = fmt.Sprintf("%v", typed["child"]), fmt.Sprintf("%v", typed["parent"])
+			default:
+				continue
+			}
+
+			table := valueTable(dependencyValues, childPath)
+			if table == nil {
+				*warnings = append(*warnings, fmt.Errorf("import-values of %s:%s: %s has no table %s", c.Metadata.Name, c.Metadata.Version, key, childPath))
+				continue
+			}
+
+			imported = helm.MergeValues(nestValues(parentPath, copyValues(table)), imported)
+		}
+	}
+
+	return imported, nil
+}
+
+// valueTable returns the map at a dotted path of values, or nil if there is none
+func valueTable(values map[string]interface{}, path string) map[string]interface{} {
+
+	table := values
+
+	for _, key := range strings.Split(path, ".") {
+		if key == "" {
+			continue
+		}
+		child, isMap := table[key].(map[string]interface{})
+		if !isMap {
+			return nil
+		}
+		table = child
+	}
+
+	return table
+}
+
+// nestValues puts values at a dotted path, `.` or an empty path is the top level
+func nestValues(path string, values map[string]interface{}) map[string]interface{} {
+
+	keys := strings.Split(path, ".")
+
+	for i := len(keys) - 1; i >= 0; i-- {
+		if keys[i] != "" {
+			values = map[string]interface{}{keys[i]: values}
+		}
+	}
+
+	return values
+}
+
+// insertImportedValues adds imported values to the defaults, values of the chart itself win
+func insertImportedValues(docs map[string]*ConfigDoc, imported map[string]interface{}) (map[string]*ConfigDoc, error) {
+
+	for globalKey, configDoc := range docs {
+		importedValue, err := findValueForKey(globalKey, imported, false)
+		if err != nil {
+			return nil, err
+		}
+		configDoc.DefaultValue = mergeValues(configDoc.DefaultValue, importedValue)
+	}
+
+	return docs, nil
+}
@@ -0,0 +1,51 @@
+package generator
+
+import (
+	"helm.sh/helm/v3/pkg/chart"
+	"reflect"
+	"testing"
+)
+
+func Test_importedValues(t *testing.T) {
+	type args struct {
+		importValues []interface{}
+		childValues  string
+		values       string
+	}
+	tests := []struct {
+		name        string
+		args        args
+		want        map[string]interface{}
+		wantWarning bool
+	}{
+		{name: "exports", args: args{importValues: []interface{}{"data"}, childValues: `exports: {data: {port: 5432}}`, values: `{}`},
+			want: parseJson(`{"port": 5432}`)},
+		{name: "child_parent", args: args{importValues: []interface{}{map[string]interface{}{"child": "service", "parent": "database.service"}}, childValues: `service: {port: 5432}`, values: `{}`},
+			want: parseJson(`{"database": {"service": {"port": 5432}}}`)},
+		{name: "overridden_by_parent", args: args{importValues: []interface{}{map[string]interface{}{"child": "service", "parent": "."}}, childValues: `service: {port: 5432}`, values: `{"db": {"service": {"port": 3306}}}`},
+			want: parseJson(`{"port": 3306}`)},
+		{name: "first_import_wins", args: args{importValues: []interface{}{"data", map[string]interface{}{"child": "service", "parent": "."}}, childValues: `{exports: {data: {port: 1}}, service: {port: 2, type: ClusterIP}}`, values: `{}`},
+			want: parseJson(`{"port": 1, "type": "ClusterIP"}`)},
+		{name: "missing_table", args: args{importValues: []interface{}{"data"}, childValues: `service: {port: 5432}`, values: `{}`},
+			want: map[string]interface{}{}, wantWarning: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &chart.Chart{Metadata: &chart.Metadata{Name: "db", Version: "1.0.0"}, Raw: []*chart.File{{Name: "values.yaml", Data: []byte(tt.args.childValues)}}}
+			c := &chart.Chart{Metadata: &chart.Metadata{Name: "app", Version: "1.0.0", Dependencies: []*chart.Dependency{{Name: "db", ImportValues: tt.args.importValues}}}}
+			c.SetDependencies(db)
+
+			var warnings Errors
+			got, err := importedValues(c, parseJson(tt.args.values), &warnings)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("importedValues() = %v, want %v", got, tt.want)
+			}
+			if (len(warnings) > 0) != tt.wantWarning {
+				t.Errorf("importedValues() warnings = %v, wantWarning %v", warnings, tt.wantWarning)
+			}
+		})
+	}
+}
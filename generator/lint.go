@@ -0,0 +1,66 @@
+package generator
+
+import (
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// severities of findings
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// RuleInvalidChart is used for problems preventing the validation of a chart, e.g. unparsable yaml
+const RuleInvalidChart = "invalid-chart"
+
+// Finding is a single problem of a chart reported by lint.
+type Finding struct {
+	Chart    string
+	Key      string
+	Rule     string
+	Severity string
+	// File is relative to the chart directory, e.g. `charts/db/values.yaml`
+	File    string
+	Message string
+	// Locations are further files the key is found in, e.g. templates using it
+	Locations []string
+}
+
+// LintChart validates a chart and its dependencies and returns every finding.
+// Problems of dependencies are warnings unless `flags.VerifyDependencies` is set.
+func LintChart(c *chart.Chart, flags CommandFlags) []*Finding {
+
+	var errs, warnings Errors
+
+	chartDocs := generateChartDocs(c, newChartTree(), nil, "", flags, &errs, &warnings)
+	collectGlobals(chartDocs, flags, &errs, &warnings)
+
+	findings := toFindings(errs, SeverityError)
+	return append(findings, toFindings(warnings, SeverityWarning)...)
+}
+
+func toFindings(errs Errors, severity string) []*Finding {
+
+	var findings []*Finding
+
+	for _, err := range errs {
+		validationError, isValidationError := err.(*ValidationError)
+		if !isValidationError {
+			findings = append(findings, &Finding{Rule: RuleInvalidChart, Severity: severity, Message: err.Error()})
+			continue
+		}
+		for _, key := range validationError.Keys {
+			findings = append(findings, &Finding{
+				Chart:     validationError.Chart,
+				Key:       key,
+				Rule:      validationError.Rule,
+				Severity:  severity,
+				File:      validationError.File,
+				Message:   validationError.Message,
+				Locations: validationError.Files[key],
+			})
+		}
+	}
+
+	return findings
+}
@@ -0,0 +1,26 @@
+package generator
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_toFindings(t *testing.T) {
+
+	errs := Errors{
+		&ValidationError{Chart: "app:1.0.0", Rule: RuleUndocumentedTemplateValue, Message: "undocumented", File: "values.yaml", Keys: []string{"a", "b"},
+			Files: map[string][]string{"b": {"templates/b.yaml"}}},
+		errors.New("unable to read values"),
+	}
+
+	want := []*Finding{
+		{Chart: "app:1.0.0", Key: "a", Rule: RuleUndocumentedTemplateValue, Severity: SeverityWarning, File: "values.yaml", Message: "undocumented"},
+		{Chart: "app:1.0.0", Key: "b", Rule: RuleUndocumentedTemplateValue, Severity: SeverityWarning, File: "values.yaml", Message: "undocumented", Locations: []string{"templates/b.yaml"}},
+		{Rule: RuleInvalidChart, Severity: SeverityWarning, Message: "unable to read values"},
+	}
+
+	if got := toFindings(errs, SeverityWarning); !reflect.DeepEqual(got, want) {
+		t.Errorf("toFindings() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,63 @@
+package generator
+
+import (
+	"fmt"
+	"github.com/random-dwi/helm-doc/helm"
+	"gopkg.in/yaml.v2"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	"sort"
+	"strings"
+)
+
+// validateExampleRendering renders the templates of a chart with the examples merged over the defaults.
+// Template errors and rendered resources which are no valid yaml are returned.
+func validateExampleRendering(c *chart.Chart, allValues map[string]map[string]interface{}, valueSource []string, examples map[string]interface{}) []string {
+
+	// the defaults of the chart itself are added by helm, values of parents override them
+	values := map[string]interface{}{}
+	for _, source := range valueSource[1:] {
+		values = helm.MergeValues(values, copyValues(allValues[source]))
+	}
+	values = helm.MergeValues(values, copyValues(examples))
+
+	rendered, err := helm.RenderTemplates(c, values)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	var problems []string
+
+	templatePrefix := c.Metadata.Name + "/"
+
+	for name, content := range rendered {
+		if !strings.HasPrefix(name, templatePrefix+"templates/") || strings.HasSuffix(name, "NOTES.txt") {
+			continue
+		}
+		for _, manifest := range releaseutil.SplitManifests(content) {
+			var resource map[string]interface{}
+			if err := yaml.Unmarshal([]byte(manifest), &resource); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", strings.TrimPrefix(name, templatePrefix), err))
+			}
+		}
+	}
+	sort.Strings(problems)
+
+	return problems
+}
+
+// copyValues copies nested maps, as merging values modifies them
+func copyValues(values map[string]interface{}) map[string]interface{} {
+
+	copied := make(map[string]interface{}, len(values))
+
+	for key, value := range values {
+		if valueMap, isMap := value.(map[string]interface{}); isMap {
+			copied[key] = copyValues(valueMap)
+		} else {
+			copied[key] = value
+		}
+	}
+
+	return copied
+}
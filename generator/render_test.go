@@ -0,0 +1,60 @@
+package generator
+
+import (
+	"helm.sh/helm/v3/pkg/chart"
+	"reflect"
+	"testing"
+)
+
+func Test_validateExampleRendering(t *testing.T) {
+	type args struct {
+		template     string
+		parentValues string
+		examples     string
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{name: "valid", args: args{template: "port: {{ .Values.port }}", parentValues: `{}`, examples: `{"port": 8080}`}, want: nil},
+		{name: "template_error", args: args{template: `port: {{ required "name is required" .Values.name }}`, parentValues: `{}`, examples: `{"port": 8080}`},
+			want: []string{"execution error at (app/templates/test.yaml:1:9): name is required"}},
+		{name: "invalid_yaml", args: args{template: "name: {{ .Values.name }}", parentValues: `{}`, examples: `{"name": "a: b"}`},
+			want: []string{"templates/test.yaml: yaml: mapping values are not allowed in this context"}},
+		{name: "parent_values", args: args{template: `port: {{ required "name is required" .Values.name }}`, parentValues: `{"name": "parent"}`, examples: `{}`}, want: nil},
+		{name: "examples_win", args: args{template: "name: {{ .Values.name }}", parentValues: `{"name": "parent"}`, examples: `{"name": "a: b"}`},
+			want: []string{"templates/test.yaml: yaml: mapping values are not allowed in this context"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &chart.Chart{
+				Metadata:  &chart.Metadata{APIVersion: "v2", Name: "app", Version: "1.0.0"},
+				Templates: []*chart.File{{Name: "templates/test.yaml", Data: []byte(tt.args.template)}},
+				Values:    map[string]interface{}{"port": 80},
+			}
+			allValues := map[string]map[string]interface{}{"app": {"port": 80}, "parent": parseJson(tt.args.parentValues)}
+			if got := validateExampleRendering(c, allValues, []string{"app", "parent"}, parseJson(tt.args.examples)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("validateExampleRendering() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_validateExampleRendering_dependency(t *testing.T) {
+	db := &chart.Chart{
+		Metadata:  &chart.Metadata{APIVersion: "v2", Name: "db", Version: "1.0.0"},
+		Templates: []*chart.File{{Name: "templates/test.yaml", Data: []byte("name: {{ .Values.name }}")}},
+	}
+	app := &chart.Chart{
+		Metadata:  &chart.Metadata{APIVersion: "v2", Name: "app", Version: "1.0.0"},
+		Templates: []*chart.File{{Name: "templates/test.yaml", Data: []byte("port: 80")}},
+	}
+	app.SetDependencies(db)
+
+	allValues := map[string]map[string]interface{}{"db": {}, "app": {}}
+	want := []string{"templates/test.yaml: yaml: mapping values are not allowed in this context"}
+	if got := validateExampleRendering(db, allValues, []string{"db", "app"}, parseJson(`{"name": "a: b"}`)); !reflect.DeepEqual(got, want) {
+		t.Errorf("validateExampleRendering() = %v, want %v", got, want)
+	}
+}
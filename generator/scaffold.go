@@ -0,0 +1,233 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/chart"
+	"sort"
+	"strings"
+)
+
+// placeholder used for scaffolded descriptions and examples
+const TodoPlaceholder = "TODO"
+
+// ScaffoldDefinitions adds a TODO description to definitions.yaml for every undocumented default value.
+// Existing definitions are kept including their order and comments. The added keys are returned.
+func ScaffoldDefinitions(c *chart.Chart, flags CommandFlags) ([]byte, []string, error) {
+
+	values, err := chartValues(c)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rawDefinitions := findFile(c.Files, "definitions.yaml")
+
+	definitions, err := parseYaml(rawDefinitions)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to read definitions for %s:%s: %v", c.Metadata.Name, c.Metadata.Version, err)
+	}
+
+	definitions, err = withCommentDefinitions(c, definitions, flags)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	missingKeys := validateDefaultValues("", definitions, values)
+	sort.Strings(missingKeys)
+
+	scaffolded, err := addPlaceholders(rawDefinitions, missingKeys, values)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to scaffold definitions for %s:%s: %v", c.Metadata.Name, c.Metadata.Version, err)
+	}
+
+	return scaffolded, missingKeys, nil
+}
+
+// ScaffoldExamples adds a TODO example to examples.yaml for every documented key without default value.
+// Existing examples are kept including their order and comments. The added keys are returned.
+func ScaffoldExamples(c *chart.Chart, rawDefinitions []byte, flags CommandFlags) ([]byte, []string, error) {
+
+	values, err := chartValues(c)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	definitions, err := parseYaml(rawDefinitions)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to read definitions for %s:%s: %v", c.Metadata.Name, c.Metadata.Version, err)
+	}
+
+	definitions, err = withCommentDefinitions(c, definitions, flags)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	docs, err := convertToConfigDocs("", definitions)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid definitions for %s:%s: %v", c.Metadata.Name, c.Metadata.Version, err)
+	}
+
+	rawExamples := findFile(c.Files, "examples.yaml")
+
+	examples, err := parseYaml(rawExamples)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to read examples for %s:%s: %v", c.Metadata.Name, c.Metadata.Version, err)
+	}
+
+	var missingKeys []string
+
+	for globalKey := range docs {
+		defaultValue, err := findValueForKey(globalKey, values, false)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to read default values for %s:%s: %v", c.Metadata.Name, c.Metadata.Version, err)
+		}
+		exampleValue, err := findValueForKey(globalKey, examples, false)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to read examples for %s:%s: %v", c.Metadata.Name, c.Metadata.Version, err)
+		}
+		if defaultValue == nil && exampleValue == nil {
+			missingKeys = append(missingKeys, globalKey)
+		}
+	}
+	sort.Strings(missingKeys)
+
+	scaffolded, err := addPlaceholders(rawExamples, missingKeys, values)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to scaffold examples for %s:%s: %v", c.Metadata.Name, c.Metadata.Version, err)
+	}
+
+	return scaffolded, missingKeys, nil
+}
+
+// chartValues reads the default values of a chart without the values of its dependencies
+func chartValues(c *chart.Chart) (map[string]interface{}, error) {
+
+	values, err := parseYaml(findRawValues(c))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read values for %s:%s: %v", c.Metadata.Name, c.Metadata.Version, err)
+	}
+
+	for _, name := range dependencyNames(c) {
+		delete(values, name)
+	}
+
+	return values, nil
+}
+
+// addPlaceholders inserts a TODO value for every key into a yaml document
+func addPlaceholders(document []byte, keys []string, values map[string]interface{}) ([]byte, error) {
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(document, &root); err != nil {
+		return nil, err
+	}
+
+	if root.Kind == 0 {
+		root = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+
+	if root.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("expected a map at the top level")
+	}
+
+	if len(keys) > 0 {
+		// an empty document like `{}` would otherwise stay in flow style
+		root.Content[0].Style &^= yaml.FlowStyle
+	}
+
+	for _, key := range keys {
+		if err := insertNode(root.Content[0], splitKey(key, values), TodoPlaceholder); err != nil {
+			return nil, fmt.Errorf("%s: %v", key, err)
+		}
+	}
+
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&root); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// splitKey splits a global key into the keys used in the values, which may contain dots themselves
+func splitKey(globalKey string, values map[string]interface{}) []string {
+
+	keys := strings.Split(globalKey, ".")
+
+	for i := range keys {
+		leftMostKeys := keys[:len(keys)-i]
+		joinedKey := strings.Join(leftMostKeys, ".")
+		baseKey, isArray := isArrayKey(joinedKey)
+
+		if subValues, exists := values[baseKey]; exists || i == len(keys)-1 {
+			subKey := strings.TrimPrefix(strings.TrimPrefix(globalKey, joinedKey), ".")
+			if subKey == "" {
+				return []string{joinedKey}
+			}
+			if isArray {
+				if subArray, isSubArray := subValues.([]interface{}); isSubArray && len(subArray) > 0 {
+					subValues = subArray[0]
+				}
+			}
+			subMap, _ := subValues.(map[string]interface{})
+			return append([]string{joinedKey}, splitKey(subKey, subMap)...)
+		}
+	}
+
+	return keys
+}
+
+// insertNode adds a scalar at the given key path, creating maps and single element arrays on the way
+func insertNode(mapping *yaml.Node, keys []string, value string) error {
+
+	baseKey, isArray := isArrayKey(keys[0])
+
+	var child *yaml.Node
+	for i := 0; i < len(mapping.Content)-1; i += 2 {
+		if mapping.Content[i].Value == baseKey {
+			child = mapping.Content[i+1]
+			break
+		}
+	}
+
+	if child == nil {
+		child = &yaml.Node{Kind: yaml.MappingNode}
+		if isArray {
+			child = &yaml.Node{Kind: yaml.SequenceNode}
+		}
+		if len(keys) == 1 && !isArray {
+			child = &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+		}
+		mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: baseKey}, child)
+	}
+
+	if isArray {
+		if child.Kind != yaml.SequenceNode {
+			return fmt.Errorf("expected array at %s", baseKey)
+		}
+		if len(child.Content) == 0 {
+			element := &yaml.Node{Kind: yaml.MappingNode}
+			if len(keys) == 1 {
+				element = &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+			}
+			child.Content = append(child.Content, element)
+		}
+		child = child.Content[0]
+	}
+
+	if len(keys) == 1 {
+		return nil
+	}
+
+	if child.Kind != yaml.MappingNode {
+		return fmt.Errorf("expected map at %s", baseKey)
+	}
+
+	return insertNode(child, keys[1:], value)
+}
@@ -0,0 +1,39 @@
+package generator
+
+import (
+	"testing"
+)
+
+func Test_addPlaceholders(t *testing.T) {
+	type args struct {
+		document string
+		keys     []string
+		values   string
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{name: "empty_document", args: args{document: "", keys: []string{"key"}, values: `{"key": 1}`}, want: "key: TODO\n"},
+		{name: "empty_map", args: args{document: "{}\n", keys: []string{"key"}, values: `{"key": 1}`}, want: "key: TODO\n"},
+		{name: "keep_existing", args: args{document: "# docs\nparent:\n  child1: docs # keep\n", keys: []string{"parent.child2"}, values: `{}`},
+			want: "# docs\nparent:\n  child1: docs # keep\n  child2: TODO\n"},
+		{name: "array", args: args{document: "", keys: []string{"parent[].child1", "parent[].child2"}, values: `{"parent": [{"child1": 1, "child2": 2}]}`},
+			want: "parent:\n  - child1: TODO\n    child2: TODO\n"},
+		{name: "key_with_dots", args: args{document: "", keys: []string{"annotations.example.com/name"}, values: `{"annotations": {"example.com/name": "value"}}`},
+			want: "annotations:\n  example.com/name: TODO\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := addPlaceholders([]byte(tt.args.document), tt.args.keys, parseJson(tt.args.values))
+			if err != nil {
+				t.Errorf("addPlaceholders() error = %v", err)
+				return
+			}
+			if string(got) != tt.want {
+				t.Errorf("addPlaceholders() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,540 @@
+package generator
+
+import (
+	"fmt"
+	"helm.sh/helm/v3/pkg/chart"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template/parse"
+)
+
+// valueScope describes what the dot or a variable refers to while walking a template
+type valueScope struct {
+	// root is the top level context of a template, which has `.Values`
+	root bool
+	// known is set if the scope is `.Values` or one of its children
+	known bool
+	// key is the key of `.Values` the scope refers to, elements of arrays and maps end with `[]`
+	key string
+}
+
+var rootScope = valueScope{root: true}
+
+// `kind: Deployment` of a rendered resource
+var kindRegex = regexp.MustCompile(`(?m)^kind:\s*["']?([A-Za-z0-9]+)`)
+
+// `---` separating the resources of a template
+var documentSeparatorRegex = regexp.MustCompile(`(?m)^---`)
+
+// templateReferences holds the keys of `.Values` referenced by the templates of a chart.
+// Elements of arrays and maps are referenced with `[]`, e.g. `hosts[].name`.
+type templateReferences struct {
+	// files maps the keys to the templates using them
+	files map[string][]string
+	// kinds maps the keys to the kinds of the resources rendered with them
+	kinds map[string][]string
+}
+
+// templateDocument is a part of a template rendering a single resource
+type templateDocument struct {
+	end  int
+	kind string
+}
+
+// templateInclude is an `include` or `template` of a named template
+type templateInclude struct {
+	from string
+	name string
+	kind string
+}
+
+type templateAnalyzer struct {
+	file       string
+	tree       string
+	documents  []templateDocument
+	position   parse.Pos
+	variables  map[string]valueScope
+	references *templateReferences
+	// keys referenced by named templates, which get the kinds of the templates including them
+	namedKeys map[string][]string
+	includes  []templateInclude
+}
+
+func newTemplateReferences() *templateReferences {
+	return &templateReferences{files: map[string][]string{}, kinds: map[string][]string{}}
+}
+
+// findTemplateReferences returns the keys of `.Values` referenced by the templates of a chart
+func findTemplateReferences(c *chart.Chart) (*templateReferences, error) {
+
+	references := newTemplateReferences()
+	namedKeys := map[string][]string{}
+	var includes []templateInclude
+
+	for _, file := range c.Templates {
+		trees := map[string]*parse.Tree{}
+		tree := parse.New(file.Name)
+		// helm functions are unknown to the parser, they are not needed to find references
+		tree.Mode = parse.SkipFuncCheck
+		if _, err := tree.Parse(string(file.Data), "", "", trees); err != nil {
+			return nil, fmt.Errorf("unable to parse template %s: %v", file.Name, err)
+		}
+
+		var names []string
+		for name := range trees {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			// named templates are usually included with the root context
+			analyzer := &templateAnalyzer{
+				file:       file.Name,
+				tree:       name,
+				documents:  templateDocuments(string(file.Data)),
+				variables:  map[string]valueScope{"$": rootScope},
+				references: references,
+				namedKeys:  namedKeys,
+			}
+			if trees[name].Root != nil {
+				analyzer.walk(trees[name].Root, rootScope)
+			}
+			includes = append(includes, analyzer.includes...)
+		}
+	}
+
+	// named templates render the kinds of the templates including them, also if included by other named templates
+	namedKinds := map[string][]string{}
+	for changed := true; changed; {
+		changed = false
+		for _, include := range includes {
+			kinds := namedKinds[include.from]
+			if include.kind != "" {
+				kinds = []string{include.kind}
+			}
+			for _, kind := range kinds {
+				if !containsString(namedKinds[include.name], kind) {
+					namedKinds[include.name] = append(namedKinds[include.name], kind)
+					changed = true
+				}
+			}
+		}
+	}
+
+	for name, keys := range namedKeys {
+		for _, key := range keys {
+			for _, kind := range namedKinds[name] {
+				references.addKind(key, kind)
+			}
+		}
+	}
+
+	return references, nil
+}
+
+// templateDocuments splits a template into the resources it renders
+func templateDocuments(template string) []templateDocument {
+
+	var documents []templateDocument
+
+	start := 0
+	for _, separator := range append(documentSeparatorRegex.FindAllStringIndex(template, -1), []int{len(template), len(template)}) {
+		document := templateDocument{end: separator[0]}
+		if match := kindRegex.FindStringSubmatch(template[start:separator[0]]); match != nil {
+			document.kind = match[1]
+		}
+		documents = append(documents, document)
+		start = separator[1]
+	}
+
+	return documents
+}
+
+func (r *templateReferences) addFile(key string, file string) {
+	if !containsString(r.files[key], file) {
+		r.files[key] = append(r.files[key], file)
+	}
+}
+
+func (r *templateReferences) addKind(key string, kind string) {
+	if !containsString(r.kinds[key], kind) {
+		r.kinds[key] = append(r.kinds[key], kind)
+		sort.Strings(r.kinds[key])
+	}
+}
+
+// kind returns the kind of the resource rendered at the current position, named templates have no kind on their own
+func (a *templateAnalyzer) kind() string {
+
+	if a.tree != a.file {
+		return ""
+	}
+
+	for _, document := range a.documents {
+		if int(a.position) <= document.end {
+			return document.kind
+		}
+	}
+
+	return ""
+}
+
+func (a *templateAnalyzer) walk(node parse.Node, dot valueScope) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			a.walk(child, dot)
+		}
+	case *parse.ActionNode:
+		a.position = n.Pos
+		a.walkPipe(n.Pipe, dot)
+	case *parse.TemplateNode:
+		a.position = n.Pos
+		a.includes = append(a.includes, templateInclude{from: a.tree, name: n.Name, kind: a.kind()})
+		if n.Pipe != nil {
+			a.walkPipe(n.Pipe, dot)
+		}
+	case *parse.IfNode:
+		a.position = n.Pos
+		a.walkPipe(n.Pipe, dot)
+		a.walk(n.List, dot)
+		a.walk(n.ElseList, dot)
+	case *parse.WithNode:
+		a.position = n.Pos
+		scope := a.walkPipe(n.Pipe, dot)
+		a.walk(n.List, scope)
+		a.walk(n.ElseList, dot)
+	case *parse.RangeNode:
+		a.position = n.Pos
+		element := a.walkRangePipe(n.Pipe, dot)
+		a.walk(n.List, element)
+		a.walk(n.ElseList, dot)
+	}
+}
+
+// walkPipe records the references of a pipeline and returns the scope of its result
+func (a *templateAnalyzer) walkPipe(pipe *parse.PipeNode, dot valueScope) valueScope {
+
+	var result valueScope
+
+	for _, command := range pipe.Cmds {
+		result = a.walkCommand(command, dot)
+	}
+
+	if len(pipe.Decl) == 1 {
+		a.variables[pipe.Decl[0].Ident[0]] = result
+	}
+
+	return result
+}
+
+// walkRangePipe records the references of a range pipeline and returns the scope of its elements
+func (a *templateAnalyzer) walkRangePipe(pipe *parse.PipeNode, dot valueScope) valueScope {
+
+	var element valueScope
+
+	for _, command := range pipe.Cmds {
+		element = a.walkCommand(command, dot)
+	}
+	if element.known {
+		element.key = element.key + "[]"
+	}
+
+	// `range $element := ...` or `range $index, $element := ...`
+	if len(pipe.Decl) > 0 {
+		a.variables[pipe.Decl[len(pipe.Decl)-1].Ident[0]] = element
+	}
+
+	return element
+}
+
+func (a *templateAnalyzer) walkCommand(command *parse.CommandNode, dot valueScope) valueScope {
+
+	var scopes []valueScope
+	for _, arg := range command.Args {
+		scopes = append(scopes, a.walkArg(arg, dot))
+	}
+
+	if len(command.Args) == 1 {
+		return scopes[0]
+	}
+
+	// `include "name" .` renders a named template
+	if identifier, isIdentifier := command.Args[0].(*parse.IdentifierNode); isIdentifier && identifier.Ident == "include" {
+		if name, isString := command.Args[1].(*parse.StringNode); isString {
+			a.includes = append(a.includes, templateInclude{from: a.tree, name: name.Text, kind: a.kind()})
+		}
+	}
+
+	// `index .Values "a" "b"` is the same as `.Values.a.b`
+	if identifier, isIdentifier := command.Args[0].(*parse.IdentifierNode); isIdentifier && identifier.Ident == "index" && len(command.Args) > 1 {
+		scope := scopes[1]
+		for _, arg := range command.Args[2:] {
+			switch key := arg.(type) {
+			case *parse.StringNode:
+				scope = a.resolve(scope, []string{key.Text})
+			case *parse.NumberNode:
+				if scope.known {
+					scope.key = scope.key + "[]"
+				}
+			default:
+				return valueScope{}
+			}
+		}
+		return scope
+	}
+
+	return valueScope{}
+}
+
+func (a *templateAnalyzer) walkArg(arg parse.Node, dot valueScope) valueScope {
+	switch n := arg.(type) {
+	case *parse.DotNode:
+		return dot
+	case *parse.FieldNode:
+		return a.resolve(dot, n.Ident)
+	case *parse.VariableNode:
+		scope, exists := a.variables[n.Ident[0]]
+		if !exists {
+			return valueScope{}
+		}
+		return a.resolve(scope, n.Ident[1:])
+	case *parse.ChainNode:
+		return a.resolve(a.walkArg(n.Node, dot), n.Field)
+	case *parse.PipeNode:
+		return a.walkPipe(n, dot)
+	}
+	return valueScope{}
+}
+
+// resolve follows fields starting at a scope and records the referenced key
+func (a *templateAnalyzer) resolve(scope valueScope, fields []string) valueScope {
+
+	if len(fields) == 0 {
+		return scope
+	}
+
+	if scope.root {
+		if fields[0] != "Values" {
+			return valueScope{}
+		}
+		scope = valueScope{known: true}
+		fields = fields[1:]
+	}
+
+	if !scope.known || len(fields) == 0 {
+		return scope
+	}
+
+	key := strings.Join(fields, ".")
+	if scope.key != "" {
+		key = scope.key + "." + key
+	}
+
+	a.references.addFile(key, a.file)
+	if a.tree != a.file {
+		if !containsString(a.namedKeys[a.tree], key) {
+			a.namedKeys[a.tree] = append(a.namedKeys[a.tree], key)
+		}
+	} else if kind := a.kind(); kind != "" {
+		a.references.addKind(key, kind)
+	}
+
+	return valueScope{known: true, key: key}
+}
+
+// conditionReferences returns the keys of a dependency used by the condition of its declaration, like `enabled` for `db.enabled`
+func conditionReferences(declaration *chart.Dependency) []string {
+
+	var keys []string
+
+	if declaration == nil {
+		return keys
+	}
+
+	prefix := declaration.Name + "."
+	if declaration.Alias != "" {
+		prefix = declaration.Alias + "."
+	}
+	for _, condition := range strings.Split(declaration.Condition, ",") {
+		condition = strings.TrimSpace(condition)
+		if strings.HasPrefix(condition, prefix) {
+			keys = append(keys, strings.TrimPrefix(condition, prefix))
+		}
+	}
+
+	return keys
+}
+
+// findUndocumentedReferences returns the referenced keys that are neither documented nor have a default value.
+// Keys are known if they are documented or have a default value, if a parent is documented as a whole
+// or if they are a parent of a known key.
+func findUndocumentedReferences(references map[string][]string, docs map[string]*ConfigDoc, values []map[string]interface{}, ignoredPrefixes []string) []string {
+
+	var knownKeys [][]string
+	for key := range docs {
+		knownKeys = append(knownKeys, keyTokens(key))
+	}
+	for _, value := range values {
+		for _, key := range valueLeafKeys("", value) {
+			knownKeys = append(knownKeys, keyTokens(key))
+		}
+	}
+
+	var undocumented []string
+
+	for key := range references {
+		tokens := keyTokens(key)
+		if containsString(ignoredPrefixes, tokens[0]) {
+			continue
+		}
+		known := false
+		for _, knownKey := range knownKeys {
+			if tokensOverlap(tokens, knownKey) {
+				known = true
+				break
+			}
+		}
+		if !known {
+			undocumented = append(undocumented, key)
+		}
+	}
+	sort.Strings(undocumented)
+
+	return undocumented
+}
+
+// findUnusedValues returns the documented keys and default values no template references.
+// A key is used if a template references it, one of its parents or one of its children.
+func findUnusedValues(references map[string][]string, docs map[string]*ConfigDoc, values map[string]interface{}) []string {
+
+	keys := valueLeafKeys("", values)
+	for key := range docs {
+		if !containsString(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+
+	var referencedKeys [][]string
+	for key := range references {
+		referencedKeys = append(referencedKeys, keyTokens(key))
+	}
+
+	var unused []string
+
+	for _, key := range keys {
+		tokens := keyTokens(key)
+		// global values are meant for dependencies, which are checked on their own
+		if tokens[0] == "global" {
+			continue
+		}
+		used := false
+		for _, referencedKey := range referencedKeys {
+			if tokensOverlap(referencedKey, tokens) {
+				used = true
+				break
+			}
+		}
+		if !used {
+			unused = append(unused, key)
+		}
+	}
+	sort.Strings(unused)
+
+	return unused
+}
+
+// insertUsage adds the templates and kinds of resources using a key, its parents or its children to the docs
+func insertUsage(docs map[string]*ConfigDoc, references *templateReferences) {
+
+	for key, configDoc := range docs {
+		tokens := keyTokens(key)
+		for referencedKey, files := range references.files {
+			if !tokensOverlap(keyTokens(referencedKey), tokens) {
+				continue
+			}
+			for _, file := range files {
+				if !containsString(configDoc.Templates, file) {
+					configDoc.Templates = append(configDoc.Templates, file)
+				}
+			}
+			for _, kind := range references.kinds[referencedKey] {
+				if !containsString(configDoc.Kinds, kind) {
+					configDoc.Kinds = append(configDoc.Kinds, kind)
+				}
+			}
+		}
+		sort.Strings(configDoc.Templates)
+		sort.Strings(configDoc.Kinds)
+	}
+}
+
+// valueLeafKeys returns the keys of all values which are no maps, empty maps are leafs as well
+func valueLeafKeys(parentKey string, values map[string]interface{}) []string {
+
+	var keys []string
+
+	for key, value := range values {
+		globalKey := key
+		if parentKey != "" {
+			globalKey = parentKey + "." + key
+		}
+
+		switch typed := value.(type) {
+		case map[string]interface{}:
+			if len(typed) == 0 {
+				keys = append(keys, globalKey)
+			} else {
+				keys = append(keys, valueLeafKeys(globalKey, typed)...)
+			}
+		case []interface{}:
+			var elementKeys []string
+			for _, element := range typed {
+				if elementMap, isMap := element.(map[string]interface{}); isMap {
+					elementKeys = append(elementKeys, valueLeafKeys(globalKey+"[]", elementMap)...)
+				}
+			}
+			if len(elementKeys) == 0 {
+				// arrays of scalars are leafs
+				elementKeys = []string{globalKey}
+			}
+			keys = append(keys, elementKeys...)
+		default:
+			keys = append(keys, globalKey)
+		}
+	}
+
+	return keys
+}
+
+// keyTokens splits `hosts[].name` into `hosts`, `[]` and `name`
+func keyTokens(key string) []string {
+
+	var tokens []string
+
+	for _, part := range strings.Split(key, ".") {
+		baseKey, isArray := isArrayKey(part)
+		tokens = append(tokens, baseKey)
+		if isArray {
+			tokens = append(tokens, "[]")
+		}
+	}
+
+	return tokens
+}
+
+// tokensOverlap checks if one key is a prefix of the other, referenced elements `[]` match any key of a map
+func tokensOverlap(referenced []string, known []string) bool {
+
+	for i := 0; i < len(referenced) && i < len(known); i++ {
+		if referenced[i] != known[i] && referenced[i] != "[]" {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,200 @@
+package generator
+
+import (
+	"helm.sh/helm/v3/pkg/chart"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func Test_findTemplateReferences(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		want     []string
+		wantErr  bool
+	}{
+		{name: "field", template: `{{ .Values.image.tag }}`, want: []string{"image.tag"}},
+		{name: "pipeline", template: `{{ .Values.name | default "app" | quote }}`, want: []string{"name"}},
+		{name: "default", template: `{{ default "app" .Values.name }}`, want: []string{"name"}},
+		{name: "index", template: `{{ index .Values "image" "tag" }}`, want: []string{"image", "image.tag"}},
+		{name: "index_array", template: `{{ (index .Values.hosts 0).name }}`, want: []string{"hosts", "hosts[].name"}},
+		{name: "with", template: `{{ with .Values.image }}{{ .tag }}{{ else }}{{ .Values.tag }}{{ end }}`, want: []string{"image", "image.tag", "tag"}},
+		{name: "range", template: `{{ range .Values.hosts }}{{ .name }}{{ $.Values.domain }}{{ end }}`, want: []string{"domain", "hosts", "hosts[].name"}},
+		{name: "range_variables", template: `{{ range $i, $host := .Values.hosts }}{{ $host.name }}{{ end }}`, want: []string{"hosts", "hosts[].name"}},
+		{name: "variable", template: `{{ $image := .Values.image }}{{ $image.tag }}`, want: []string{"image", "image.tag"}},
+		{name: "if", template: `{{ if .Values.enabled }}{{ .Release.Name }}{{ end }}`, want: []string{"enabled"}},
+		{name: "define", template: `{{ define "name" }}{{ .Values.nameOverride }}{{ end }}{{ include "name" . }}`, want: []string{"nameOverride"}},
+		{name: "other_context", template: `{{ range .Files }}{{ .Values.ignored }}{{ end }}`, want: nil},
+		{name: "invalid", template: `{{ .Values.name `, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &chart.Chart{Templates: []*chart.File{{Name: "templates/test.yaml", Data: []byte(tt.template)}}}
+			references, err := findTemplateReferences(c)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("findTemplateReferences() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			var got []string
+			for key := range references.files {
+				got = append(got, key)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findTemplateReferences() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_findTemplateReferences_kinds(t *testing.T) {
+
+	c := &chart.Chart{Templates: []*chart.File{
+		{Name: "templates/_helpers.tpl", Data: []byte(`{{ define "labels" }}app: {{ .Values.name }}{{ include "version" . }}{{ end }}
+{{ define "version" }}version: {{ .Values.version }}{{ end }}`)},
+		{Name: "templates/app.yaml", Data: []byte(`kind: Deployment
+spec:
+  replicas: {{ .Values.replicas }}
+  labels: {{ include "labels" . }}
+---
+apiVersion: v1
+kind: "Service"
+spec:
+  type: {{ .Values.service.type }}
+  labels: {{ template "version" . }}`)},
+		{Name: "templates/NOTES.txt", Data: []byte(`{{ .Values.service.type }}`)},
+	}}
+
+	references, err := findTemplateReferences(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantKinds := map[string][]string{
+		"replicas":     {"Deployment"},
+		"service.type": {"Service"},
+		"name":         {"Deployment"},
+		"version":      {"Deployment", "Service"},
+	}
+	if !reflect.DeepEqual(references.kinds, wantKinds) {
+		t.Errorf("findTemplateReferences() kinds = %v, want %v", references.kinds, wantKinds)
+	}
+	if want := []string{"templates/app.yaml", "templates/NOTES.txt"}; !reflect.DeepEqual(references.files["service.type"], want) {
+		t.Errorf("findTemplateReferences() files = %v, want %v", references.files["service.type"], want)
+	}
+}
+
+func Test_insertUsage(t *testing.T) {
+
+	references := newTemplateReferences()
+	references.files = map[string][]string{"resources": {"templates/app.yaml"}, "image.tag": {"templates/app.yaml", "templates/job.yaml"}}
+	references.kinds = map[string][]string{"resources": {"Deployment"}, "image.tag": {"Deployment", "Job"}}
+
+	docs := map[string]*ConfigDoc{"resources.limits": {}, "image": {}, "unused": {}}
+	insertUsage(docs, references)
+
+	if !reflect.DeepEqual(docs["resources.limits"].Kinds, []string{"Deployment"}) {
+		t.Errorf("insertUsage() kinds = %v, want kinds of parent", docs["resources.limits"].Kinds)
+	}
+	if !reflect.DeepEqual(docs["image"].Templates, []string{"templates/app.yaml", "templates/job.yaml"}) {
+		t.Errorf("insertUsage() templates = %v, want templates of children", docs["image"].Templates)
+	}
+	if docs["unused"].Templates != nil || docs["unused"].Kinds != nil {
+		t.Errorf("insertUsage() = %v, want no usage", docs["unused"])
+	}
+}
+
+func Test_findUndocumentedReferences(t *testing.T) {
+	type args struct {
+		references string
+		docs       map[string]*ConfigDoc
+		values     string
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{name: "documented", args: args{references: `{"image.tag": ["a.yaml"]}`, docs: map[string]*ConfigDoc{"image.tag": {}}, values: `{}`}, want: nil},
+		{name: "default_value", args: args{references: `{"image.tag": ["a.yaml"]}`, values: `{"image": {"tag": "1.0"}}`}, want: nil},
+		{name: "parent_of_known", args: args{references: `{"image": ["a.yaml"]}`, values: `{"image": {"tag": "1.0"}}`}, want: nil},
+		{name: "child_of_empty_map", args: args{references: `{"resources.limits": ["a.yaml"]}`, values: `{"resources": {}}`}, want: nil},
+		{name: "child_of_documented", args: args{references: `{"resources.limits": ["a.yaml"]}`, docs: map[string]*ConfigDoc{"resources": {}}, values: `{}`}, want: nil},
+		{name: "array_element", args: args{references: `{"hosts[].name": ["a.yaml"]}`, values: `{"hosts": [{"name": "a"}]}`}, want: nil},
+		{name: "map_element", args: args{references: `{"env[].value": ["a.yaml"]}`, values: `{"env": {"A": {"value": "a"}}}`}, want: nil},
+		{name: "dependency", args: args{references: `{"db.enabled": ["a.yaml"]}`, values: `{}`}, want: nil},
+		{name: "missing", args: args{references: `{"image.pullPolicy": ["a.yaml", "b.yaml"], "name": ["a.yaml"]}`, values: `{"image": {"tag": "1.0"}}`},
+			want: []string{"image.pullPolicy", "name"}},
+		{name: "missing_array_element", args: args{references: `{"hosts[].path": ["a.yaml"]}`, values: `{"hosts": [{"name": "a"}]}`}, want: []string{"hosts[].path"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			references := map[string][]string{}
+			for key, files := range parseJson(tt.args.references) {
+				for _, file := range files.([]interface{}) {
+					references[key] = append(references[key], file.(string))
+				}
+			}
+			values := []map[string]interface{}{parseJson(tt.args.values)}
+			if got := findUndocumentedReferences(references, tt.args.docs, values, []string{"db"}); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findUndocumentedReferences() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_findUnusedValues(t *testing.T) {
+	type args struct {
+		references []string
+		docs       map[string]*ConfigDoc
+		values     string
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{name: "used", args: args{references: []string{"image.tag"}, values: `{"image": {"tag": "1.0"}}`}, want: nil},
+		{name: "parent_used", args: args{references: []string{"resources"}, values: `{"resources": {"limits": {"cpu": 1}}}`}, want: nil},
+		{name: "child_used", args: args{references: []string{"resources.limits"}, docs: map[string]*ConfigDoc{"resources": {}}, values: `{}`}, want: nil},
+		{name: "array_element_used", args: args{references: []string{"hosts[].name"}, values: `{"hosts": [{"name": "a"}]}`}, want: nil},
+		{name: "global", args: args{values: `{"global": {"region": "eu"}}`}, want: nil},
+		{name: "unused_default", args: args{references: []string{"image.tag"}, values: `{"image": {"tag": "1.0", "pullPolicy": "Always"}}`}, want: []string{"image.pullPolicy"}},
+		{name: "unused_definition", args: args{docs: map[string]*ConfigDoc{"legacy.enabled": {}}, values: `{}`}, want: []string{"legacy.enabled"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			references := map[string][]string{}
+			for _, key := range tt.args.references {
+				references[key] = []string{"templates/test.yaml"}
+			}
+			if got := findUnusedValues(references, tt.args.docs, parseJson(tt.args.values)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findUnusedValues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_conditionReferences(t *testing.T) {
+	tests := []struct {
+		name        string
+		declaration *chart.Dependency
+		want        []string
+	}{
+		{name: "condition", declaration: &chart.Dependency{Name: "db", Condition: "db.enabled, global.db.enabled"}, want: []string{"enabled"}},
+		{name: "alias", declaration: &chart.Dependency{Name: "redis", Alias: "cache", Condition: "cache.enabled"}, want: []string{"enabled"}},
+		{name: "no_condition", declaration: &chart.Dependency{Name: "db"}, want: nil},
+		{name: "not_declared", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := conditionReferences(tt.declaration); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("conditionReferences() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package generator
+
+import (
+	"fmt"
+	"helm.sh/helm/v3/pkg/chart"
+	"sort"
+	"strings"
+)
+
+// RuleUnknownValue is used for values set by a user which are not defined by the chart or its dependencies
+const RuleUnknownValue = "unknown-value"
+
+// valuesValidation collects the problems of the values of a user while walking the chart tree
+type valuesValidation struct {
+	unknown    []string
+	typeErrors []string
+	// unknownGlobals counts the charts not knowing a global key, knownGlobals are the global keys known by any chart
+	unknownGlobals map[string]int
+	knownGlobals   []string
+	charts         int
+}
+
+// ValidateValues checks the values of a user against the definitions and defaults of a chart and its dependencies.
+// Keys neither defined nor having a default are reported with the most similar known key, e.g. `resouces` for `resources`.
+// Values not matching the type, enum or pattern of their definition are reported as well. file is the name of the values file.
+func ValidateValues(c *chart.Chart, rawValues []byte, file string, flags CommandFlags) error {
+
+	values, err := parseYaml(rawValues)
+	if err != nil {
+		return fmt.Errorf("unable to read %s: %v", file, err)
+	}
+
+	// problems of the chart itself are not the concern of its users
+	chartDocs, err := GenerateChartDocs(c, withoutVerification(flags))
+	if err != nil {
+		return err
+	}
+
+	validation := &valuesValidation{unknownGlobals: map[string]int{}}
+
+	globals, _ := values["global"].(map[string]interface{})
+
+	if err := validation.validateChart(chartDocs, "", values, globals, flags); err != nil {
+		return err
+	}
+
+	for key, charts := range validation.unknownGlobals {
+		if charts == validation.charts {
+			validation.unknown = append(validation.unknown, withSuggestion("", key, validation.knownGlobals))
+		}
+	}
+	sort.Strings(validation.unknown)
+	sort.Strings(validation.typeErrors)
+
+	chartName := fmt.Sprintf("%s:%s", c.Metadata.Name, c.Metadata.Version)
+
+	var errs Errors
+	if len(validation.unknown) > 0 {
+		errs = append(errs, &ValidationError{Chart: chartName, Rule: RuleUnknownValue, Message: "values not defined by the chart detected", File: file, Keys: validation.unknown})
+	}
+	if len(validation.typeErrors) > 0 {
+		errs = append(errs, &ValidationError{Chart: chartName, Rule: RuleTypeMismatch, Message: "values not matching their definition detected", File: file, Keys: validation.typeErrors})
+	}
+
+	return errs.ErrorOrNil()
+}
+
+// validateChart validates the values of a chart below prefix, the values of dependencies are validated by the dependencies
+func (v *valuesValidation) validateChart(chartDocs *ChartDocs, prefix string, values map[string]interface{}, globals map[string]interface{}, flags CommandFlags) error {
+
+	c := chartDocs.Chart
+	v.charts++
+
+	definitions, err := findDefinitions(c, flags)
+	if err != nil && findFile(c.Files, "definitions.yaml") == nil {
+		// dependencies without definitions, e.g. upstream charts, are validated against their defaults
+		definitions = map[string]interface{}{}
+	} else if err != nil {
+		return fmt.Errorf("unable to read definitions for %s:%s: %v", c.Metadata.Name, c.Metadata.Version, err)
+	}
+	defaults, err := parseYaml(findRawValues(c))
+	if err != nil {
+		return fmt.Errorf("unable to read values for %s:%s: %v", c.Metadata.Name, c.Metadata.Version, err)
+	}
+
+	var known []string
+	for key := range chartDocs.Docs {
+		known = append(known, key)
+	}
+	known = append(known, valueLeafKeys("", defaults)...)
+	known = append(known, conditionReferences(chartDocs.Declaration)...)
+	if prefix == "" && hasTags(chartDocs) {
+		known = append(known, "tags")
+	}
+
+	ignored := append(dependencyNames(c), "global")
+	if prefix == "" {
+		ignored = append(ignored, "tags")
+	}
+	ownValues := map[string]interface{}{}
+	for key, value := range values {
+		if !containsString(ignored, key) {
+			ownValues[key] = value
+		}
+	}
+
+	for _, key := range validateDefaultValues("", definitions, ownValues) {
+		if !isKnownKey(key, known) {
+			v.unknown = append(v.unknown, withSuggestion(prefix, key, known))
+		}
+	}
+
+	if globals != nil {
+		for _, key := range validateDefaultValues("global", definitions, globals) {
+			if !isKnownKey(key, known) {
+				v.unknownGlobals[key]++
+			}
+		}
+		for _, key := range known {
+			if strings.HasPrefix(key, globalPrefix) {
+				v.knownGlobals = append(v.knownGlobals, key)
+			}
+		}
+	}
+
+	for key, configDoc := range chartDocs.Docs {
+		scope := values
+		if strings.HasPrefix(key, globalPrefix) {
+			scope = map[string]interface{}{"global": globals}
+		}
+		for _, value := range collectValues(keyTokens(key), scope) {
+			if err := validateValue(configDoc, value); err != nil {
+				v.typeErrors = append(v.typeErrors, fmt.Sprintf("%s: %v", PrefixKey(prefix, key), err))
+			}
+		}
+	}
+
+	for _, dependency := range chartDocs.Dependencies {
+		key := valuesKey(dependency.Chart, dependency.Declaration)
+		dependencyValues, _ := values[key].(map[string]interface{})
+		if err := v.validateChart(dependency, PrefixKey(prefix, key), dependencyValues, globals, flags); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func hasTags(chartDocs *ChartDocs) bool {
+	for _, dependency := range chartDocs.Dependencies {
+		if dependency.Declaration != nil && len(dependency.Declaration.Tags) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// isKnownKey returns true if the key, one of its parents or one of its children is known
+func isKnownKey(key string, known []string) bool {
+	tokens := keyTokens(key)
+	for _, knownKey := range known {
+		if tokensOverlap(tokens, keyTokens(knownKey)) {
+			return true
+		}
+	}
+	return false
+}
+
+// collectValues returns the values at the path of tokens, for every element of arrays
+func collectValues(tokens []string, value interface{}) []interface{} {
+
+	if len(tokens) == 0 {
+		return []interface{}{value}
+	}
+
+	if tokens[0] == "[]" {
+		var collected []interface{}
+		array, _ := value.([]interface{})
+		for _, element := range array {
+			collected = append(collected, collectValues(tokens[1:], element)...)
+		}
+		return collected
+	}
+
+	values, isMap := value.(map[string]interface{})
+	if !isMap {
+		return nil
+	}
+	child, exists := values[tokens[0]]
+	if !exists || child == nil {
+		return nil
+	}
+
+	return collectValues(tokens[1:], child)
+}
+
+// withSuggestion adds the most similar known key to an unknown key of the chart at prefix. The first unknown part
+// of the key is compared with the known keys having the same parent, e.g. `resouces.limits.cpu` with `resources`.
+func withSuggestion(prefix string, key string, known []string) string {
+
+	tokens := strings.Split(key, ".")
+
+	prefixes := map[string]bool{}
+	for _, knownKey := range known {
+		knownTokens := strings.Split(knownKey, ".")
+		for i := range knownTokens {
+			prefixes[strings.Join(knownTokens[:i+1], ".")] = true
+		}
+	}
+
+	for i := range tokens {
+		if prefixes[strings.Join(tokens[:i+1], ".")] {
+			continue
+		}
+
+		parent := strings.Join(tokens[:i], ".")
+		best, bestDistance := "", len(tokens[i])/3+2
+		for candidate := range prefixes {
+			candidateTokens := strings.Split(candidate, ".")
+			if len(candidateTokens) != i+1 || strings.Join(candidateTokens[:i], ".") != parent {
+				continue
+			}
+			distance := levenshtein(tokens[i], candidateTokens[i])
+			if distance < bestDistance || (distance == bestDistance && best != "" && candidate < best) {
+				best, bestDistance = candidate, distance
+			}
+		}
+		if best == "" {
+			break
+		}
+		suggestion := strings.Join(append([]string{best}, tokens[i+1:]...), ".")
+		return fmt.Sprintf("%s (did you mean %s?)", PrefixKey(prefix, key), PrefixKey(prefix, suggestion))
+	}
+
+	return PrefixKey(prefix, key)
+}
+
+// levenshtein returns the number of edits to turn a into b
+func levenshtein(a string, b string) int {
+
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+
+	return previous[len(b)]
+}
+
+func minInt(values ...int) int {
+	result := values[0]
+	for _, value := range values[1:] {
+		if value < result {
+			result = value
+		}
+	}
+	return result
+}
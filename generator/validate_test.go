@@ -0,0 +1,83 @@
+package generator
+
+import (
+	"helm.sh/helm/v3/pkg/chart"
+	"reflect"
+	"testing"
+)
+
+func Test_ValidateValues(t *testing.T) {
+	db := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "db", Version: "1.0.0"},
+		Raw:      []*chart.File{{Name: "values.yaml", Data: []byte("port: 5432\n")}},
+		Files:    []*chart.File{{Name: "definitions.yaml", Data: []byte("port: {description: db port, type: integer}\nglobal: {region: deployment region}\n")}},
+	}
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "app", Version: "1.0.0", Dependencies: []*chart.Dependency{{Name: "db", Condition: "db.enabled"}}},
+		Raw:      []*chart.File{{Name: "values.yaml", Data: []byte("replicaCount: 1\nresources: {}\nhosts:\n  - name: example.com\n")}},
+		Files: []*chart.File{{Name: "definitions.yaml", Data: []byte("replicaCount: {description: number of replicas, type: integer}\n" +
+			"resources: resources of the pod\nhosts:\n  - name: {description: host name, type: string, pattern: \"^[a-z.]+$\"}\n")}},
+	}
+	// upstream charts often have no definitions.yaml
+	cache := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "cache", Version: "1.0.0"},
+		Raw:      []*chart.File{{Name: "values.yaml", Data: []byte("auth:\n  user: admin\nreplicas: 1\n")}},
+	}
+	c.SetDependencies(db, cache)
+
+	tests := []struct {
+		name   string
+		values string
+		want   Errors
+	}{
+		{name: "valid", values: "replicaCount: 2\nresources: {limits: {cpu: 1}}\nhosts: [{name: a.com}]\ndb: {enabled: true, port: 3306}\nglobal: {region: eu}\n"},
+		{name: "unknown", values: "replicaCont: 2\nresouces: {limits: {cpu: 1}}\nhosts: [{nmae: a.com}]\ndb: {prot: 3306}\nglobal: {regoin: eu}\nother: true\n",
+			want: Errors{&ValidationError{Chart: "app:1.0.0", Rule: RuleUnknownValue, Message: "values not defined by the chart detected", File: "my-values.yaml",
+				Keys: []string{"db.prot (did you mean db.port?)", "global.regoin (did you mean global.region?)", "hosts[].nmae (did you mean hosts[].name?)",
+					"other", "replicaCont (did you mean replicaCount?)", "resouces.limits.cpu (did you mean resources.limits.cpu?)"}}}},
+		{name: "dependency_without_definitions", values: "cache: {replicas: 2, auth: {usr: x, user: y}, extra: true}\n",
+			want: Errors{&ValidationError{Chart: "app:1.0.0", Rule: RuleUnknownValue, Message: "values not defined by the chart detected", File: "my-values.yaml",
+				Keys: []string{"cache.auth.usr (did you mean cache.auth.user?)", "cache.extra"}}}},
+		{name: "type_mismatch", values: "replicaCount: two\nhosts: [{name: a.com}, {name: A_COM}]\ndb: {port: \"3306\"}\n",
+			want: Errors{&ValidationError{Chart: "app:1.0.0", Rule: RuleTypeMismatch, Message: "values not matching their definition detected", File: "my-values.yaml",
+				Keys: []string{"db.port: 3306 is not of type integer", "hosts[].name: A_COM does not match pattern ^[a-z.]+$", "replicaCount: two is not of type integer"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateValues(c, []byte(tt.values), "my-values.yaml", CommandFlags{})
+			var got Errors
+			if err != nil {
+				var isErrors bool
+				if got, isErrors = err.(Errors); !isErrors {
+					t.Fatal(err)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateValues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_withSuggestion(t *testing.T) {
+	known := []string{"image.repository", "image.tag", "resources", "replicaCount"}
+	tests := []struct {
+		name   string
+		prefix string
+		key    string
+		want   string
+	}{
+		{name: "misspelled_leaf", key: "image.tga", want: "image.tga (did you mean image.tag?)"},
+		{name: "misspelled_parent", key: "imgae.tag", want: "imgae.tag (did you mean image.tag?)"},
+		{name: "child_of_misspelled", key: "resouces.limits.cpu", want: "resouces.limits.cpu (did you mean resources.limits.cpu?)"},
+		{name: "dependency", prefix: "db", key: "replicaCont", want: "db.replicaCont (did you mean db.replicaCount?)"},
+		{name: "not_similar", key: "affinity", want: "affinity"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := withSuggestion(tt.prefix, tt.key, known); got != tt.want {
+				t.Errorf("withSuggestion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,23 +1,30 @@
 package helm
 
 import (
-	"errors"
 	"fmt"
+	"github.com/Masterminds/semver/v3"
 	"github.com/random-dwi/helm-doc/output"
-	"k8s.io/helm/pkg/downloader"
-	"k8s.io/helm/pkg/getter"
-	helm_env "k8s.io/helm/pkg/helm/environment"
-	"k8s.io/helm/pkg/helm/helmpath"
-	"k8s.io/helm/pkg/repo"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/engine"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/helmpath"
+	"helm.sh/helm/v3/pkg/registry"
+	"helm.sh/helm/v3/pkg/repo"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
-var helmHome = helmpath.Home(os.Getenv("HELM_HOME"))
+// settings are read from the environment helm passes to plugins,
+// e.g. HELM_REPOSITORY_CONFIG, HELM_REPOSITORY_CACHE and HELM_REGISTRY_CONFIG
+var settings = cli.New()
 
-var settings = helm_env.EnvSettings{
-	Home: helmHome,
+func Settings() *cli.EnvSettings {
+	return settings
 }
 
 // locateChartPath looks for a chart directory in known places, and returns either the full path or an error.
@@ -27,75 +34,71 @@ var settings = helm_env.EnvSettings{
 // Order of resolution:
 // - current working directory
 // - if path is absolute or begins with '.', error out here
-// - chart repos in $HELM_HOME
-// - URL
+// - URL or OCI reference
+// - chart repos in $HELM_REPOSITORY_CONFIG
 //
 // If 'verify' is true, this will attempt to also verify the chart.
 func LocateChartPath(repoURL, username, password, name, version string, verify bool, keyring,
 	certFile, keyFile, caFile string) (string, error) {
-	name = strings.TrimSpace(name)
-	version = strings.TrimSpace(version)
-	if fi, err := os.Stat(name); err == nil {
-		abs, err := filepath.Abs(name)
-		if err != nil {
-			return abs, err
-		}
-		if verify {
-			if fi.IsDir() {
-				return "", errors.New("cannot verify a directory")
-			}
-			if _, err := downloader.VerifyChart(abs, keyring); err != nil {
-				return "", err
-			}
-		}
-		return abs, nil
-	}
-	if filepath.IsAbs(name) || strings.HasPrefix(name, ".") {
-		return name, fmt.Errorf("path %q not found", name)
-	}
 
-	crepo := filepath.Join(helmHome.Repository(), name)
-	if _, err := os.Stat(crepo); err == nil {
-		return filepath.Abs(crepo)
+	registryClient, err := registry.NewClient(
+		registry.ClientOptDebug(settings.Debug),
+		registry.ClientOptEnableCache(true),
+		registry.ClientOptWriter(os.Stderr),
+		registry.ClientOptCredentialsFile(settings.RegistryConfig),
+	)
+	if err != nil {
+		return "", err
 	}
 
-	dl := downloader.ChartDownloader{
-		HelmHome: helmHome,
-		Out:      os.Stdout,
-		Keyring:  keyring,
-		Getters:  getter.All(settings),
-		Username: username,
-		Password: password,
-	}
-	if verify {
-		dl.Verify = downloader.VerifyAlways
-	}
-	if repoURL != "" {
-		chartURL, err := repo.FindChartInAuthRepoURL(repoURL, username, password, name, version,
-			certFile, keyFile, caFile, getter.All(settings))
-		if err != nil {
+	show := action.NewShowWithConfig(action.ShowAll, &action.Configuration{RegistryClient: registryClient})
+	show.RepoURL = repoURL
+	show.Username = username
+	show.Password = password
+	show.Version = version
+	show.Verify = verify
+	show.Keyring = keyring
+	show.CertFile = certFile
+	show.KeyFile = keyFile
+	show.CaFile = caFile
+
+	filename, err := show.LocateChart(name, settings)
+	if err != nil {
+		if settings.Debug {
 			return "", err
 		}
-		name = chartURL
+		return "", fmt.Errorf("failed to locate %q (hint: running `helm repo update` may help): %v", name, err)
 	}
 
-	if _, err := os.Stat(helmHome.Archive()); os.IsNotExist(err) {
-		os.MkdirAll(helmHome.Archive(), 0744)
-	}
+	output.Debugf("located %s at %s", name, filename)
 
-	filename, _, err := dl.DownloadTo(name, version, helmHome.Archive())
-	if err == nil {
-		lname, err := filepath.Abs(filename)
-		if err != nil {
-			return filename, err
+	return filename, nil
+}
+
+// RenderTemplates renders the templates of a chart offline like `helm template` does.
+// Dependencies are not rendered, except library charts which provide named templates.
+func RenderTemplates(c *chart.Chart, values map[string]interface{}) (map[string]string, error) {
+
+	// the loaded chart is not modified, it is used for the docs afterwards.
+	// A dependency is rendered without its parent, otherwise the engine scopes the values to the key of the dependency.
+	ownChart := chart.Chart{Raw: c.Raw, Metadata: c.Metadata, Lock: c.Lock, Templates: c.Templates, Values: c.Values, Schema: c.Schema, Files: c.Files}
+	var libraries []*chart.Chart
+	for _, dependency := range c.Dependencies() {
+		if dependency.Metadata.Type == "library" {
+			library := *dependency
+			libraries = append(libraries, &library)
 		}
-		output.Debugf("Fetched %s to %s", name, filename)
-		return lname, nil
-	} else if settings.Debug {
-		return filename, err
+	}
+	ownChart.SetDependencies(libraries...)
+
+	options := chartutil.ReleaseOptions{Name: "release-name", Namespace: "default", Revision: 1, IsInstall: true}
+
+	renderValues, err := chartutil.ToRenderValues(&ownChart, values, options, chartutil.DefaultCapabilities)
+	if err != nil {
+		return nil, err
 	}
 
-	return filename, fmt.Errorf("failed to download %q (hint: running `helm repo update` may help)", name)
+	return engine.Render(&ownChart, renderValues)
 }
 
 // Copied from Helm.
@@ -129,3 +132,66 @@ func MergeValues(dest map[string]interface{}, src map[string]interface{}) map[st
 	}
 	return dest
 }
+
+// ChartVersions returns the versions of a chart in its repository from oldest to newest, limited to from and to if given.
+// The chart is either found in the repository at repoURL or given as `repo/chart` of a repository added to helm.
+func ChartVersions(repoURL, username, password, name, from, to string, devel bool, certFile, keyFile, caFile string) ([]string, error) {
+
+	indexFile, chartName := "", name
+
+	if repoURL != "" {
+		chartRepository, err := repo.NewChartRepository(&repo.Entry{URL: repoURL, Username: username, Password: password,
+			CertFile: certFile, KeyFile: keyFile, CAFile: caFile}, getter.All(settings))
+		if err != nil {
+			return nil, err
+		}
+		chartRepository.CachePath = settings.RepositoryCache
+		if indexFile, err = chartRepository.DownloadIndexFile(); err != nil {
+			return nil, fmt.Errorf("unable to download the index of %s: %v", repoURL, err)
+		}
+	} else {
+		parts := strings.SplitN(name, "/", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%q is not of the form repo/chart and --repo is not set", name)
+		}
+		indexFile, chartName = filepath.Join(settings.RepositoryCache, helmpath.CacheIndexFile(parts[0])), parts[1]
+	}
+
+	index, err := repo.LoadIndexFile(indexFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the index of %s (hint: running `helm repo update` may help): %v", name, err)
+	}
+
+	var fromVersion, toVersion *semver.Version
+	if from != "" {
+		if fromVersion, err = semver.NewVersion(from); err != nil {
+			return nil, fmt.Errorf("invalid version %q: %v", from, err)
+		}
+	}
+	if to != "" {
+		if toVersion, err = semver.NewVersion(to); err != nil {
+			return nil, fmt.Errorf("invalid version %q: %v", to, err)
+		}
+	}
+
+	var versions []*semver.Version
+
+	for _, chartVersion := range index.Entries[chartName] {
+		version, err := semver.NewVersion(chartVersion.Version)
+		if err != nil || (version.Prerelease() != "" && !devel) {
+			continue
+		}
+		if (fromVersion != nil && version.LessThan(fromVersion)) || (toVersion != nil && version.GreaterThan(toVersion)) {
+			continue
+		}
+		versions = append(versions, version)
+	}
+	sort.Sort(semver.Collection(versions))
+
+	var names []string
+	for _, version := range versions {
+		names = append(names, version.Original())
+	}
+
+	return names, nil
+}
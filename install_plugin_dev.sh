@@ -1,6 +1,7 @@
 #!/bin/sh
+PLUGINS="$(helm env HELM_PLUGINS)"
 helm plugin remove doc
-rm `helm home`/plugins/helm-doc
+rm "$PLUGINS/helm-doc"
 DIR="$( cd "$( dirname "${BASH_SOURCE[0]}" )" && pwd )"
-ln -s $DIR `helm home`/plugins/helm-doc
-echo "created symlink to `helm home`/plugins/helm-doc"
\ No newline at end of file
+ln -s $DIR "$PLUGINS/helm-doc"
+echo "created symlink to $PLUGINS/helm-doc"
\ No newline at end of file
@@ -4,9 +4,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"gopkg.in/yaml.v2"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
+	"strings"
 )
 
 var DebugLogger StdLogger = log.New(ioutil.Discard, "[doc] ", log.LstdFlags)
@@ -44,21 +46,36 @@ func Exitf(code int, msg string, args ...interface{}) {
 }
 
 func PrintObject(object interface{}, format string) {
+	if err := FprintObject(IoStreams.Out, object, format); err != nil {
+		Failf("%v", err)
+	}
+}
+
+// FprintObject writes an object as yaml or json
+func FprintObject(out io.Writer, object interface{}, format string) error {
+
+	var serialized []byte
+	var err error
+
 	if format == "yaml" {
-		yamlString, err := yaml.Marshal(object)
+		serialized, err = yaml.Marshal(object)
 		if err != nil {
-			Failf("unable to format yaml: %v", err)
+			return fmt.Errorf("unable to format yaml: %v", err)
 		}
-		_, _ = fmt.Fprintln(IoStreams.Out, string(yamlString))
 	} else if format == "json" {
-		jsonString, err := json.MarshalIndent(object, "", "\t")
+		serialized, err = json.MarshalIndent(object, "", "\t")
 		if err != nil {
-			Failf("unable to format json: %v", err)
+			return fmt.Errorf("unable to format json: %v", err)
 		}
-		_, _ = fmt.Fprintln(IoStreams.Out, string(jsonString))
 	} else {
-		Failf("unknown format: %v", format)
+		return fmt.Errorf("unknown format: %v", format)
+	}
+
+	if _, err := fmt.Fprintln(out, strings.TrimSuffix(string(serialized), "\n")); err != nil {
+		return fmt.Errorf("failed to write output: %v", err)
 	}
+
+	return nil
 }
 
 func PrintStrings(args ...string) {
@@ -0,0 +1,191 @@
+package writer
+
+import (
+	"fmt"
+	"github.com/random-dwi/helm-doc/generator"
+	"github.com/random-dwi/helm-doc/output"
+	"gopkg.in/yaml.v2"
+	"io"
+	"strings"
+)
+
+// WriteUpgradeGuide writes a section per version listing the changes since the previous version, newest version first.
+// The diffs are ordered from oldest to newest.
+func WriteUpgradeGuide(out io.Writer, chartName string, diffs []*generator.Diff, format string) error {
+
+	switch format {
+	case "json", "yaml":
+		return output.FprintObject(out, diffs, format)
+	case "markdown":
+	default:
+		return fmt.Errorf("unknown upgrade guide format %q, supported formats: %s", format, strings.Join(DiffFormats, ", "))
+	}
+
+	g := MarkdownWriter{writer: out}
+
+	if err := g.WriteChapter("Upgrade guide for "+chartName, 1); err != nil {
+		return err
+	}
+
+	for i := len(diffs) - 1; i >= 0; i-- {
+		if err := g.writeVersion(diffs[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (g MarkdownWriter) writeVersion(diff *generator.Diff) error {
+
+	if err := g.WriteChapter(diff.To, 2); err != nil {
+		return err
+	}
+	if err := g.fprintf("upgrading from %s\n\n", diff.From); err != nil {
+		return err
+	}
+
+	changes := map[string][]*generator.Change{}
+	for _, change := range diff.Changes {
+		changes[change.Kind] = append(changes[change.Kind], change)
+	}
+
+	if len(changes[generator.ChangeAdded]) == 0 && len(changes[generator.ChangeDeprecated]) == 0 &&
+		len(changes[generator.ChangeRemoved]) == 0 && len(changes[generator.ChangeRenamed]) == 0 && len(changes[generator.ChangeDefault]) == 0 {
+		return g.fprintf("no changes of documented values\n\n")
+	}
+
+	if added := changes[generator.ChangeAdded]; len(added) > 0 {
+		if err := g.writeChangeTable("New keys", []string{"KEY", "DESCRIPTION", "DEFAULT", "BREAKING"}, added, func(change *generator.Change) ([]string, error) {
+			defaultValue, err := toMarkdown(change.To)
+			return []string{sanitize(change.Description), defaultValue}, err
+		}); err != nil {
+			return err
+		}
+		if err := g.writeExampleSnippet(added); err != nil {
+			return err
+		}
+	}
+
+	if err := g.writeChangeTable("Deprecated keys", []string{"KEY", "DEPRECATION", "REPLACED BY", "BREAKING"}, changes[generator.ChangeDeprecated], func(change *generator.Change) ([]string, error) {
+		replacedBy := " "
+		if change.To != nil {
+			replacedBy = fmt.Sprintf("`%v`", change.To)
+		}
+		return []string{sanitize(change.Description), replacedBy}, nil
+	}); err != nil {
+		return err
+	}
+
+	removed := append(changes[generator.ChangeRemoved], changes[generator.ChangeRenamed]...)
+	if err := g.writeChangeTable("Removed keys", []string{"KEY", "REPLACED BY", "BREAKING"}, removed, func(change *generator.Change) ([]string, error) {
+		if change.OldKey != "" {
+			return []string{"`" + change.Key + "`"}, nil
+		}
+		return []string{" "}, nil
+	}); err != nil {
+		return err
+	}
+
+	return g.writeChangeTable("Changed defaults", []string{"KEY", "FROM", "TO", "BREAKING"}, changes[generator.ChangeDefault], func(change *generator.Change) ([]string, error) {
+		from, err := toMarkdown(change.From)
+		if err != nil {
+			return nil, err
+		}
+		to, err := toMarkdown(change.To)
+		return []string{from, to}, err
+	})
+}
+
+// writeChangeTable writes a table with the key, the columns returned by row and whether the change is breaking
+func (g MarkdownWriter) writeChangeTable(title string, header []string, changes []*generator.Change, row func(change *generator.Change) ([]string, error)) error {
+
+	if len(changes) == 0 {
+		return nil
+	}
+
+	if err := g.WriteChapter(title, 3); err != nil {
+		return err
+	}
+	if err := g.fprintf("|%s|\n|%s|\n", strings.Join(header, "|"), strings.Repeat("---|", len(header)-1)+"---"); err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		key := change.Key
+		if change.OldKey != "" {
+			key = change.OldKey
+		}
+		columns, err := row(change)
+		if err != nil {
+			return err
+		}
+		breaking := " "
+		if change.Breaking {
+			breaking = "**yes**"
+		}
+		if err := g.fprintf("|`%s`|%s|%s|\n", key, strings.Join(columns, "|"), breaking); err != nil {
+			return err
+		}
+	}
+
+	return g.fprintf("\n")
+}
+
+// writeExampleSnippet writes the examples of new keys as they are set in a values file
+func (g MarkdownWriter) writeExampleSnippet(added []*generator.Change) error {
+
+	values := map[string]interface{}{}
+	for _, change := range added {
+		if change.Example != nil {
+			insertSnippetValue(values, strings.Split(change.Key, "."), change.Example)
+		}
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	serialized, err := yaml.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("unable to serialize examples: %v", err)
+	}
+
+	return g.fprintf("example from `examples.yaml`:\n\n```yaml\n%s```\n\n", serialized)
+}
+
+// insertSnippetValue sets a value at the path of a key, array keys like `hosts[]` get a single element
+func insertSnippetValue(values map[string]interface{}, keys []string, value interface{}) {
+
+	key, isArray := isArrayKey(keys[0])
+
+	if len(keys) == 1 {
+		if isArray {
+			value = []interface{}{value}
+		}
+		values[key] = value
+		return
+	}
+
+	if isArray {
+		elements, _ := values[key].([]interface{})
+		if len(elements) == 0 {
+			elements = []interface{}{map[string]interface{}{}}
+			values[key] = elements
+		}
+		element, isMap := elements[0].(map[string]interface{})
+		if !isMap {
+			element = map[string]interface{}{}
+			elements[0] = element
+		}
+		insertSnippetValue(element, keys[1:], value)
+		return
+	}
+
+	child, isMap := values[key].(map[string]interface{})
+	if !isMap {
+		child = map[string]interface{}{}
+		values[key] = child
+	}
+	insertSnippetValue(child, keys[1:], value)
+}
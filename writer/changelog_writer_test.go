@@ -0,0 +1,26 @@
+package writer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_insertSnippetValue(t *testing.T) {
+
+	values := map[string]interface{}{}
+	insertSnippetValue(values, strings.Split("image.registry", "."), "docker.io")
+	insertSnippetValue(values, strings.Split("image.tag", "."), "1.0")
+	insertSnippetValue(values, strings.Split("hosts[].name", "."), "example.com")
+	insertSnippetValue(values, strings.Split("hosts[].path", "."), "/")
+	insertSnippetValue(values, strings.Split("domains[]", "."), "example.com")
+
+	want := map[string]interface{}{
+		"image":   map[string]interface{}{"registry": "docker.io", "tag": "1.0"},
+		"hosts":   []interface{}{map[string]interface{}{"name": "example.com", "path": "/"}},
+		"domains": []interface{}{"example.com"},
+	}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("insertSnippetValue() = %v, want %v", values, want)
+	}
+}
@@ -0,0 +1,86 @@
+package writer
+
+import (
+	"fmt"
+	"github.com/random-dwi/helm-doc/generator"
+	"github.com/random-dwi/helm-doc/output"
+	"io"
+	"strings"
+)
+
+// DiffFormats are the formats the changes between two versions of a chart can be written in
+var DiffFormats = []string{"markdown", "json", "yaml"}
+
+// WriteDiff writes the changes between two versions of a chart, breaking changes are listed first in markdown
+func WriteDiff(out io.Writer, diff *generator.Diff, format string) error {
+
+	switch format {
+	case "json", "yaml":
+		return output.FprintObject(out, diff, format)
+	case "markdown":
+		return writeMarkdownDiff(MarkdownWriter{writer: out}, diff)
+	default:
+		return fmt.Errorf("unknown diff format %q, supported formats: %s", format, strings.Join(DiffFormats, ", "))
+	}
+}
+
+func writeMarkdownDiff(g MarkdownWriter, diff *generator.Diff) error {
+
+	if err := g.WriteChapter(fmt.Sprintf("%s %s → %s", diff.Chart, diff.From, diff.To), 1); err != nil {
+		return err
+	}
+
+	var breaking, other []*generator.Change
+	for _, change := range diff.Changes {
+		if change.Breaking {
+			breaking = append(breaking, change)
+		} else {
+			other = append(other, change)
+		}
+	}
+
+	if len(diff.Changes) == 0 {
+		return g.fprintf("no changes of documented values\n")
+	}
+	if err := g.writeChanges("Breaking changes", breaking); err != nil {
+		return err
+	}
+	return g.writeChanges("Changes", other)
+}
+
+func (g MarkdownWriter) writeChanges(title string, changes []*generator.Change) error {
+
+	if len(changes) == 0 {
+		return nil
+	}
+
+	if err := g.WriteChapter(title, 2); err != nil {
+		return err
+	}
+	if err := g.fprintf("|KEY|CHANGE|FROM|TO|\n|---|---|---|---|\n"); err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		key := "`" + change.Key + "`"
+		if change.OldKey != "" {
+			key = fmt.Sprintf("`%s` → `%s`", change.OldKey, change.Key)
+		}
+		from, err := toMarkdown(change.From)
+		if err != nil {
+			return err
+		}
+		to, err := toMarkdown(change.To)
+		if err != nil {
+			return err
+		}
+		if change.Kind == generator.ChangeDescription {
+			from, to = sanitize(fmt.Sprintf("%v", change.From)), sanitize(fmt.Sprintf("%v", change.To))
+		}
+		if err := g.fprintf("|%s|%s|%s|%s|\n", key, change.Kind, from, to); err != nil {
+			return err
+		}
+	}
+
+	return g.fprintf("\n")
+}
@@ -2,11 +2,20 @@ package writer
 
 import (
 	"github.com/random-dwi/helm-doc/generator"
-	"k8s.io/helm/pkg/proto/hapi/chart"
+	"helm.sh/helm/v3/pkg/chart"
 )
 
+// globalsTitle is the title of the chapter of global values
+const globalsTitle = "Global values"
+
 type DocumentationWriter interface {
-	WriteChapter(title string, layer int)
-	WriteMetaData(metaData *chart.Metadata, layer int)
-	WriteDocs(docs map[string]*generator.ConfigDoc)
+	WriteChapter(title string, layer int) error
+	// WriteMetaData writes the meta data of a chart, declaration is the declaration of a dependency in its parent if declared.
+	// depth is the depth of the chart in the dependency tree, 0 for the root chart.
+	WriteMetaData(metaData *chart.Metadata, declaration *chart.Dependency, layer int, depth int) error
+	WriteDocs(docs map[string]*generator.ConfigDoc) error
+	// WriteGlobals writes the global values of all charts, which are shared by the whole tree
+	WriteGlobals(docs map[string]*generator.ConfigDoc, layer int) error
+	// Flush writes everything buffered by writers that need all charts before writing
+	Flush() error
 }
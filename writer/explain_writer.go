@@ -0,0 +1,143 @@
+package writer
+
+import (
+	"fmt"
+	"github.com/random-dwi/helm-doc/generator"
+	"github.com/random-dwi/helm-doc/output"
+	"gopkg.in/yaml.v2"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ExplainFormats are the formats the explanation of a key can be written in
+var ExplainFormats = []string{"markdown", "json", "yaml"}
+
+// WriteExplanation writes the documentation of a single key, defaults and examples are shown as they are set in a values file
+func WriteExplanation(out io.Writer, explanation *generator.Explanation, format string) error {
+
+	switch format {
+	case "json", "yaml":
+		return output.FprintObject(out, explanation, format)
+	case "markdown":
+		return writeMarkdownExplanation(MarkdownWriter{writer: out}, explanation)
+	default:
+		return fmt.Errorf("unknown explain format %q, supported formats: %s", format, strings.Join(ExplainFormats, ", "))
+	}
+}
+
+func writeMarkdownExplanation(g MarkdownWriter, explanation *generator.Explanation) error {
+
+	if err := g.WriteChapter("`"+explanation.Key+"`", 1); err != nil {
+		return err
+	}
+	if err := g.fprintf("chart: %s\n\n", strings.Join(explanation.Charts, " → ")); err != nil {
+		return err
+	}
+
+	if configDoc := explanation.Doc; configDoc != nil {
+		if configDoc.Description != "" {
+			if err := g.fprintf("%s\n\n", configDoc.Description); err != nil {
+				return err
+			}
+		}
+		if configDoc.Deprecated != "" {
+			deprecation := "**deprecated:** " + configDoc.Deprecated
+			if configDoc.ReplacedBy != "" {
+				deprecation += fmt.Sprintf(" (replaced by `%s`)", configDoc.ReplacedBy)
+			}
+			if err := g.fprintf("%s\n\n", deprecation); err != nil {
+				return err
+			}
+		}
+		if hasTypeMetadata(configDoc) {
+			if err := g.fprintf("type: %s\n\n", typeToText(configDoc)); err != nil {
+				return err
+			}
+		}
+		if len(configDoc.Charts) > 0 {
+			if err := g.fprintf("read by: %s\n\n", strings.Join(configDoc.Charts, ", ")); err != nil {
+				return err
+			}
+		}
+		if err := g.writeValueSnippet("default", explanation.Key, configDoc.DefaultValue); err != nil {
+			return err
+		}
+		if err := g.writeValueSnippet("example", explanation.Key, configDoc.ExampleValue); err != nil {
+			return err
+		}
+	}
+
+	if len(explanation.Children) == 0 {
+		return nil
+	}
+
+	if err := g.WriteChapter("Children", 2); err != nil {
+		return err
+	}
+	if err := g.fprintf("|KEY|DESCRIPTION|\n|---|---|\n"); err != nil {
+		return err
+	}
+
+	var children []string
+	for child := range explanation.Children {
+		children = append(children, child)
+	}
+	sort.Strings(children)
+
+	for _, child := range children {
+		if err := g.fprintf("|`%s`|%s|\n", child, cellText(explanation.Children[child])); err != nil {
+			return err
+		}
+	}
+
+	return g.fprintf("\n")
+}
+
+// writeValueSnippet writes a value at the path of its key, e.g. `hosts[].name` as a single element of `hosts`
+func (g MarkdownWriter) writeValueSnippet(title string, key string, value interface{}) error {
+
+	if value == nil {
+		return nil
+	}
+
+	values := map[string]interface{}{}
+	insertSnippetValue(values, strings.Split(key, "."), value)
+
+	serialized, err := yaml.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("unable to serialize %s of %s: %v", title, key, err)
+	}
+
+	return g.fprintf("%s:\n\n```yaml\n%s```\n\n", title, serialized)
+}
+
+// typeToText returns type, required, enum and pattern of a key without markup, which is not rendered in a terminal
+func typeToText(configDoc *generator.ConfigDoc) string {
+
+	var parts []string
+
+	if configDoc.Type != "" {
+		parts = append(parts, configDoc.Type)
+	}
+	if configDoc.Required {
+		parts = append(parts, "required")
+	}
+	if len(configDoc.Enum) > 0 {
+		var values []string
+		for _, value := range configDoc.Enum {
+			values = append(values, fmt.Sprintf("`%v`", value))
+		}
+		parts = append(parts, "one of: "+strings.Join(values, ", "))
+	}
+	if configDoc.Pattern != "" {
+		parts = append(parts, fmt.Sprintf("pattern: `%s`", configDoc.Pattern))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// cellText keeps a description in a single table cell without markup, which is not rendered in a terminal
+func cellText(description string) string {
+	return strings.Replace(strings.Join(strings.Fields(description), " "), "|", "\\|", -1)
+}
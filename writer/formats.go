@@ -0,0 +1,49 @@
+package writer
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// DefaultFormat is used if no output format is given
+const DefaultFormat = "markdown"
+
+// formats maps the names of output formats to their writers
+var formats = map[string]func(out io.Writer) DocumentationWriter{
+	"markdown": func(out io.Writer) DocumentationWriter { return NewMarkdownWriter(out) },
+	"json":     func(out io.Writer) DocumentationWriter { return NewObjectWriter(out, "json") },
+	"yaml":     func(out io.Writer) DocumentationWriter { return NewObjectWriter(out, "yaml") },
+	"schema":   func(out io.Writer) DocumentationWriter { return NewSchemaWriter(out) },
+	"html":     func(out io.Writer) DocumentationWriter { return NewHTMLWriter(out) },
+}
+
+// RegisterFormat makes a writer available as output format, an existing format with the same name is replaced
+func RegisterFormat(name string, newWriter func(out io.Writer) DocumentationWriter) {
+	formats[name] = newWriter
+}
+
+// Formats returns the names of all output formats
+func Formats() []string {
+
+	var names []string
+
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// NewDocumentationWriter creates the writer for an output format
+func NewDocumentationWriter(format string, out io.Writer) (DocumentationWriter, error) {
+
+	newWriter, exists := formats[format]
+	if !exists {
+		return nil, fmt.Errorf("unknown output format %q, supported formats: %s", format, strings.Join(Formats(), ", "))
+	}
+
+	return newWriter(out), nil
+}
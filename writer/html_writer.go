@@ -0,0 +1,393 @@
+package writer
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/random-dwi/helm-doc/generator"
+	"gopkg.in/yaml.v2"
+	"helm.sh/helm/v3/pkg/chart"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+)
+
+// HTMLWriter writes a self-contained html page with a table of contents,
+// a collapsible tree of values per chart, a search box and `--set` snippets.
+// The page is buffered until Flush is called, as the table of contents needs all charts.
+type HTMLWriter struct {
+	writer   io.Writer
+	sections []*htmlSection
+	// value prefixes of the current chart and its parents, e.g. ["", "db"]
+	prefixes []string
+}
+
+type htmlSection struct {
+	Anchor   string
+	Layer    int
+	Title    string
+	MetaData *chart.Metadata
+	// Declaration is the declaration of a dependency in its parent, if declared
+	Declaration *chart.Dependency
+	Values      []*htmlNode
+	prefix      string
+}
+
+type htmlNode struct {
+	Name       string
+	Key        string
+	Doc        *generator.ConfigDoc
+	Children   []*htmlNode
+	SearchText string
+}
+
+func NewHTMLWriter(writer io.Writer) *HTMLWriter {
+	return &HTMLWriter{writer: writer}
+}
+
+func (g *HTMLWriter) WriteChapter(title string, layer int) error {
+	g.sections = append(g.sections, &htmlSection{Layer: layer, Title: title})
+	return nil
+}
+
+func (g *HTMLWriter) WriteMetaData(metaData *chart.Metadata, declaration *chart.Dependency, layer int, depth int) error {
+
+	// the values of dependencies are found below their alias if given
+	valuesKey := generator.ValuesKey(metaData, declaration)
+
+	if depth < len(g.prefixes) {
+		g.prefixes = g.prefixes[:depth]
+	}
+
+	prefix := ""
+	if depth > 0 {
+		prefix = generator.PrefixKey(g.prefixes[depth-1], valuesKey)
+	}
+	g.prefixes = append(g.prefixes, prefix)
+
+	g.sections = append(g.sections, &htmlSection{
+		Anchor:      fmt.Sprintf("chart-%d", len(g.sections)),
+		Layer:       layer,
+		Title:       valuesKey,
+		MetaData:    metaData,
+		Declaration: declaration,
+		prefix:      prefix,
+	})
+	return nil
+}
+
+func (g *HTMLWriter) WriteDocs(docs map[string]*generator.ConfigDoc) error {
+
+	if len(g.sections) == 0 || g.sections[len(g.sections)-1].MetaData == nil {
+		return fmt.Errorf("docs have to be written after the meta data of their chart")
+	}
+
+	g.sections[len(g.sections)-1].Values = buildValueTree(docs)
+	return nil
+}
+
+func (g *HTMLWriter) WriteGlobals(docs map[string]*generator.ConfigDoc, layer int) error {
+
+	if len(docs) == 0 {
+		return nil
+	}
+
+	// global keys are set without the prefix of a chart
+	g.sections = append(g.sections, &htmlSection{
+		Anchor: "globals",
+		Layer:  layer,
+		Title:  globalsTitle,
+		Values: buildValueTree(docs),
+	})
+	return nil
+}
+
+func (g *HTMLWriter) Flush() error {
+
+	if err := htmlTemplate.Execute(g.writer, g.sections); err != nil {
+		return fmt.Errorf("failed to write output: %v", err)
+	}
+
+	return nil
+}
+
+// buildValueTree groups the keys by their prefix, e.g. `image.repository` and `image.tag` end up below `image`
+func buildValueTree(docs map[string]*generator.ConfigDoc) []*htmlNode {
+
+	var keysSorted []string
+
+	for key := range docs {
+		keysSorted = append(keysSorted, key)
+	}
+	sort.Strings(keysSorted)
+
+	root := &htmlNode{}
+
+	for _, key := range keysSorted {
+		current := root
+		keys := strings.Split(key, ".")
+		for i, name := range keys {
+			var child *htmlNode
+			for _, existing := range current.Children {
+				if existing.Name == name {
+					child = existing
+					break
+				}
+			}
+			if child == nil {
+				child = &htmlNode{Name: name, Key: strings.Join(keys[:i+1], ".")}
+				current.Children = append(current.Children, child)
+			}
+			current = child
+		}
+		current.Doc = docs[key]
+	}
+
+	for _, node := range root.Children {
+		addSearchText(node)
+	}
+
+	return root.Children
+}
+
+// the search text of a node contains those of its children, so a group is shown if any child matches
+func addSearchText(node *htmlNode) string {
+
+	text := []string{node.Key}
+	if node.Doc != nil {
+		text = append(text, node.Doc.Description)
+	}
+	for _, child := range node.Children {
+		text = append(text, addSearchText(child))
+	}
+
+	node.SearchText = strings.ToLower(strings.Join(text, " "))
+	return node.SearchText
+}
+
+// htmlNodeContext passes a node together with its chart section to the node template
+type htmlNodeContext struct {
+	Section *htmlSection
+	Node    *htmlNode
+}
+
+func nodeContext(section *htmlSection, node *htmlNode) htmlNodeContext {
+	return htmlNodeContext{Section: section, Node: node}
+}
+
+// html has six heading levels only
+func heading(layer int) int {
+	if layer > 6 {
+		return 6
+	}
+	return layer
+}
+
+func typeSummary(configDoc *generator.ConfigDoc) string {
+
+	var parts []string
+
+	if configDoc.Type != "" {
+		parts = append(parts, configDoc.Type)
+	}
+	if configDoc.Required {
+		parts = append(parts, "required")
+	}
+	if len(configDoc.Enum) > 0 {
+		var values []string
+		for _, value := range configDoc.Enum {
+			values = append(values, fmt.Sprintf("%v", value))
+		}
+		parts = append(parts, "one of: "+strings.Join(values, ", "))
+	}
+	if configDoc.Pattern != "" {
+		parts = append(parts, "pattern: "+configDoc.Pattern)
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// SetSnippet returns the `--set` argument for the example or default value of a node,
+// keys of dependencies are prefixed with the chart name
+func (s *htmlSection) SetSnippet(node *htmlNode) string {
+
+	if node.Doc == nil {
+		return ""
+	}
+
+	value := node.Doc.ExampleValue
+	if value == nil {
+		value = node.Doc.DefaultValue
+	}
+	if value == nil {
+		return ""
+	}
+
+	key := strings.Replace(node.Key, "[]", "[0]", -1)
+	// global values are shared by all charts
+	if s.prefix != "" && !strings.HasPrefix(key, "global.") {
+		key = s.prefix + "." + key
+	}
+
+	return setSnippet(key, value)
+}
+
+func setSnippet(key string, value interface{}) string {
+	switch typed := value.(type) {
+	case string:
+		escaped := strings.NewReplacer(`\`, `\\`, `,`, `\,`).Replace(typed)
+		return "--set-string " + shellQuote(key+"="+escaped)
+	case map[string]interface{}, []interface{}:
+		serialized, err := json.Marshal(typed)
+		if err != nil {
+			return ""
+		}
+		return "--set-json " + shellQuote(key+"="+string(serialized))
+	default:
+		return "--set " + shellQuote(fmt.Sprintf("%s=%v", key, typed))
+	}
+}
+
+func shellQuote(value string) string {
+	return "'" + strings.Replace(value, "'", `'\''`, -1) + "'"
+}
+
+func toHTMLValue(object interface{}) (string, error) {
+	switch object.(type) {
+	case nil:
+		return "", nil
+	case map[string]interface{}, []interface{}:
+		serialized, err := yaml.Marshal(object)
+		if err != nil {
+			return "", fmt.Errorf("unable to serialize object: %v", object)
+		}
+		return strings.TrimSuffix(string(serialized), "\n"), nil
+	default:
+		return fmt.Sprintf("%v", object), nil
+	}
+}
+
+var htmlTemplate = template.Must(template.New("page").Funcs(template.FuncMap{
+	"heading":     heading,
+	"nodeContext": nodeContext,
+	"value":       toHTMLValue,
+	"typeSummary": typeSummary,
+	"join":        strings.Join,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{with index . 0}}{{.Title}}{{end}}</title>
+<style>
+body { font-family: sans-serif; margin: 0; display: flex; }
+nav { position: sticky; top: 0; height: 100vh; overflow-y: auto; min-width: 14em; padding: 1em; background: #f4f4f4; box-sizing: border-box; }
+nav ul { list-style: none; padding-left: 0; }
+nav li { margin: 0.2em 0; }
+main { padding: 1em 2em; flex-grow: 1; }
+#search { width: 100%; padding: 0.5em; font-size: 1em; box-sizing: border-box; }
+ul.values { list-style: none; padding-left: 1.2em; }
+li.node > details > summary { cursor: pointer; }
+.description { margin: 0.2em 0; }
+.type { color: #666; font-size: 0.9em; }
+pre { background: #f4f4f4; padding: 0.4em; margin: 0.2em 0; overflow-x: auto; }
+button.copy { font-size: 0.8em; }
+</style>
+</head>
+<body>
+<nav>
+<strong>Contents</strong>
+<ul>
+{{- range .}}{{if .MetaData}}
+<li style="padding-left: {{.Layer}}em"><a href="#{{.Anchor}}">{{.Title}}</a> <span class="type">{{.MetaData.Version}}</span></li>
+{{- else if .Values}}
+<li style="padding-left: {{.Layer}}em"><a href="#{{.Anchor}}">{{.Title}}</a></li>
+{{- end}}{{end}}
+</ul>
+</nav>
+<main>
+<input type="search" id="search" placeholder="search keys and descriptions">
+{{- range $section := .}}
+{{- if .MetaData}}
+<section id="{{.Anchor}}">
+<h{{heading .Layer}}>{{.Title}}</h{{heading .Layer}}>
+<ul>
+<li><strong>Version:</strong> {{.MetaData.Version}}</li>
+<li><strong>Description:</strong> {{.MetaData.Description}}</li>
+{{- with .Declaration}}
+{{- if .Alias}}
+<li><strong>Chart:</strong> {{$section.MetaData.Name}}</li>
+{{- end}}
+{{- with .Condition}}
+<li><strong>Condition:</strong> <code>{{.}}</code></li>
+{{- end}}
+{{- with .Tags}}
+<li><strong>Tags:</strong> {{range $i, $tag := .}}{{if $i}}, {{end}}<code>{{$tag}}</code>{{end}}</li>
+{{- end}}
+{{- end}}
+</ul>
+{{- if .Values}}
+<ul class="values">
+{{- range .Values}}{{template "node" (nodeContext $section .)}}{{end}}
+</ul>
+{{- end}}
+</section>
+{{- else if .Values}}
+<section id="{{.Anchor}}">
+<h{{heading .Layer}}>{{.Title}}</h{{heading .Layer}}>
+<ul class="values">
+{{- range .Values}}{{template "node" (nodeContext $section .)}}{{end}}
+</ul>
+</section>
+{{- else}}
+<h{{heading .Layer}}>{{.Title}}</h{{heading .Layer}}>
+{{- end}}
+{{- end}}
+</main>
+<script>
+document.getElementById('search').addEventListener('input', function () {
+  var query = this.value.toLowerCase();
+  document.querySelectorAll('li.node').forEach(function (node) {
+    var match = node.dataset.search.indexOf(query) >= 0;
+    node.hidden = !match;
+    if (match && query !== '') {
+      node.querySelectorAll(':scope > details').forEach(function (details) { details.open = true; });
+    }
+  });
+});
+document.querySelectorAll('button.copy').forEach(function (button) {
+  button.addEventListener('click', function () {
+    navigator.clipboard.writeText(button.dataset.snippet).then(function () {
+      button.textContent = 'copied';
+      setTimeout(function () { button.textContent = 'copy --set'; }, 1000);
+    });
+  });
+});
+</script>
+</body>
+</html>
+{{define "node"}}
+<li class="node" data-search="{{.Node.SearchText}}">
+{{- if .Node.Children}}
+<details open>
+<summary><code>{{.Node.Name}}</code>{{with .Node.Doc}} {{.Description}}{{end}}</summary>
+{{- with .Node.Doc}}{{template "doc" $}}{{end}}
+<ul class="values">
+{{- $section := .Section}}{{range .Node.Children}}{{template "node" (nodeContext $section .)}}{{end}}
+</ul>
+</details>
+{{- else}}
+{{if and .Node.Doc .Node.Doc.Deprecated}}<del><code>{{.Node.Key}}</code></del>{{else}}<code>{{.Node.Key}}</code>{{end}}
+{{- with .Node.Doc}} <p class="description">{{.Description}}</p>{{template "doc" $}}{{end}}
+{{- end}}
+</li>
+{{- end}}
+{{define "doc"}}
+{{- with .Node.Doc.Deprecated}}<div class="type"><strong>deprecated:</strong> {{.}}{{with $.Node.Doc.ReplacedBy}} (replaced by <code>{{.}}</code>){{end}}</div>{{end}}
+{{- with typeSummary .Node.Doc}}<div class="type">{{.}}</div>{{end}}
+{{- with value .Node.Doc.DefaultValue}}<div>default:<pre>{{.}}</pre></div>{{end}}
+{{- with value .Node.Doc.ExampleValue}}<div>example:<pre>{{.}}</pre></div>{{end}}
+{{- with .Node.Doc.Charts}}<div class="type">read by: {{join . ", "}}</div>{{end}}
+{{- with .Node.Doc.Templates}}<div class="type">used in: {{with $.Node.Doc.Kinds}}{{join . ", "}} {{end}}({{join . ", "}})</div>{{end}}
+{{- with .Section.SetSnippet .Node}}<button class="copy" data-snippet="{{.}}" title="{{.}}">copy --set</button>{{end}}
+{{- end}}
+`))
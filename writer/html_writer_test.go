@@ -0,0 +1,93 @@
+package writer
+
+import (
+	"github.com/random-dwi/helm-doc/generator"
+	"helm.sh/helm/v3/pkg/chart"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func Test_HTMLWriter_WriteMetaData(t *testing.T) {
+	charts := []struct {
+		metaData    *chart.Metadata
+		declaration *chart.Dependency
+		depth       int
+	}{
+		{metaData: &chart.Metadata{Name: "app"}},
+		{metaData: &chart.Metadata{Name: "postgresql"}, declaration: &chart.Dependency{Name: "postgresql", Alias: "db"}, depth: 1},
+		{metaData: &chart.Metadata{Name: "common"}, declaration: &chart.Dependency{Name: "common"}, depth: 2},
+		{metaData: &chart.Metadata{Name: "redis"}, declaration: &chart.Dependency{Name: "redis", Alias: "cache"}, depth: 1},
+	}
+
+	g := NewHTMLWriter(ioutil.Discard)
+	for _, c := range charts {
+		if err := g.WriteMetaData(c.metaData, c.declaration, c.depth+1, c.depth); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var titles, prefixes []string
+	for _, section := range g.sections {
+		titles = append(titles, section.Title)
+		prefixes = append(prefixes, section.prefix)
+	}
+	if want := []string{"app", "db", "common", "cache"}; !reflect.DeepEqual(titles, want) {
+		t.Errorf("WriteMetaData() titles = %v, want %v", titles, want)
+	}
+	if want := []string{"", "db", "db.common", "cache"}; !reflect.DeepEqual(prefixes, want) {
+		t.Errorf("WriteMetaData() prefixes = %v, want %v", prefixes, want)
+	}
+}
+
+func Test_SetSnippet(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+		key    string
+		doc    *generator.ConfigDoc
+		want   string
+	}{
+		{name: "string", key: "image.tag", doc: &generator.ConfigDoc{DefaultValue: "1.0"}, want: `--set-string 'image.tag=1.0'`},
+		{name: "escaped_string", key: "args", doc: &generator.ConfigDoc{DefaultValue: "a,b 'c'"}, want: `--set-string 'args=a\,b '\''c'\'''`},
+		{name: "number", key: "replicas", doc: &generator.ConfigDoc{DefaultValue: 3}, want: `--set 'replicas=3'`},
+		{name: "map", key: "labels", doc: &generator.ConfigDoc{DefaultValue: map[string]interface{}{"app": "web"}}, want: `--set-json 'labels={"app":"web"}'`},
+		{name: "example_wins", key: "port", doc: &generator.ConfigDoc{DefaultValue: 80, ExampleValue: 8080}, want: `--set 'port=8080'`},
+		{name: "array", key: "hosts[].name", doc: &generator.ConfigDoc{ExampleValue: "a"}, want: `--set-string 'hosts[0].name=a'`},
+		{name: "dependency", prefix: "db", key: "port", doc: &generator.ConfigDoc{DefaultValue: 5432}, want: `--set 'db.port=5432'`},
+		{name: "dependency_global", prefix: "db", key: "global.region", doc: &generator.ConfigDoc{DefaultValue: "eu"}, want: `--set-string 'global.region=eu'`},
+		{name: "no_value", key: "password", doc: &generator.ConfigDoc{}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			section := &htmlSection{prefix: tt.prefix}
+			if got := section.SetSnippet(&htmlNode{Key: tt.key, Doc: tt.doc}); got != tt.want {
+				t.Errorf("SetSnippet() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_buildValueTree(t *testing.T) {
+	docs := map[string]*generator.ConfigDoc{
+		"image":            {Description: "the image"},
+		"image.tag":        {Description: "image tag"},
+		"image.repository": {Description: "image repository"},
+		"replicas":         {Description: "Replica Count"},
+	}
+
+	tree := buildValueTree(docs)
+
+	if len(tree) != 2 || tree[0].Key != "image" || tree[1].Key != "replicas" {
+		t.Fatalf("buildValueTree() = %v, want image and replicas", tree)
+	}
+	if tree[0].Doc != docs["image"] || len(tree[0].Children) != 2 || tree[0].Children[0].Key != "image.repository" {
+		t.Errorf("buildValueTree() image = %v, want doc and sorted children", tree[0])
+	}
+	if want := "image the image image.repository image repository image.tag image tag"; tree[0].SearchText != want {
+		t.Errorf("buildValueTree() search text = %q, want %q", tree[0].SearchText, want)
+	}
+	if tree[1].SearchText != "replicas replica count" {
+		t.Errorf("buildValueTree() search text = %q, want lower case", tree[1].SearchText)
+	}
+}
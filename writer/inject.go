@@ -0,0 +1,48 @@
+package writer
+
+import (
+	"bytes"
+	"fmt"
+)
+
+const (
+	InjectStartMarker = "<!-- helm-doc:start -->"
+	InjectEndMarker   = "<!-- helm-doc:end -->"
+)
+
+// HasInjectMarkers checks if a document contains any of the marker comments docs can be injected between.
+func HasInjectMarkers(document []byte) bool {
+	return bytes.Contains(document, []byte(InjectStartMarker)) || bytes.Contains(document, []byte(InjectEndMarker))
+}
+
+// Inject replaces the content between the marker comments of a document with the given docs.
+// Everything outside of the markers is kept as it is.
+func Inject(document []byte, docs []byte) ([]byte, error) {
+
+	for _, marker := range []string{InjectStartMarker, InjectEndMarker} {
+		if bytes.Count(document, []byte(marker)) > 1 {
+			return nil, fmt.Errorf("marker found more than once: %s", marker)
+		}
+	}
+
+	start := bytes.Index(document, []byte(InjectStartMarker))
+	if start < 0 {
+		return nil, fmt.Errorf("start marker not found: %s", InjectStartMarker)
+	}
+	start += len(InjectStartMarker)
+
+	end := bytes.Index(document[start:], []byte(InjectEndMarker))
+	if end < 0 {
+		return nil, fmt.Errorf("end marker not found after start marker: %s", InjectEndMarker)
+	}
+	end += start
+
+	var result bytes.Buffer
+	result.Write(document[:start])
+	result.WriteString("\n\n")
+	result.Write(bytes.TrimRight(docs, "\n"))
+	result.WriteString("\n\n")
+	result.Write(document[end:])
+
+	return result.Bytes(), nil
+}
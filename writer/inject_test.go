@@ -0,0 +1,39 @@
+package writer
+
+import (
+	"testing"
+)
+
+func Test_Inject(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		docs     string
+		want     string
+		wantErr  bool
+	}{
+		{name: "empty_section", document: "# intro\n<!-- helm-doc:start -->\n<!-- helm-doc:end -->\n# usage\n", docs: "docs\n\n",
+			want: "# intro\n<!-- helm-doc:start -->\n\ndocs\n\n<!-- helm-doc:end -->\n# usage\n"},
+		{name: "replace_section", document: "<!-- helm-doc:start -->\n\nold docs\n\n<!-- helm-doc:end -->", docs: "new docs\n",
+			want: "<!-- helm-doc:start -->\n\nnew docs\n\n<!-- helm-doc:end -->"},
+		{name: "idempotent", document: "<!-- helm-doc:start -->\n\ndocs\n\n<!-- helm-doc:end -->", docs: "docs\n\n",
+			want: "<!-- helm-doc:start -->\n\ndocs\n\n<!-- helm-doc:end -->"},
+		{name: "missing_start", document: "<!-- helm-doc:end -->", docs: "docs", wantErr: true},
+		{name: "missing_end", document: "<!-- helm-doc:start -->", docs: "docs", wantErr: true},
+		{name: "duplicated_start", document: "<!-- helm-doc:start --><!-- helm-doc:start --><!-- helm-doc:end -->", docs: "docs", wantErr: true},
+		{name: "duplicated_end", document: "<!-- helm-doc:start --><!-- helm-doc:end --><!-- helm-doc:end -->", docs: "docs", wantErr: true},
+		{name: "end_before_start", document: "<!-- helm-doc:end --><!-- helm-doc:start -->", docs: "docs", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Inject([]byte(tt.document), []byte(tt.docs))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Inject() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if string(got) != tt.want {
+				t.Errorf("Inject() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package writer
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"github.com/random-dwi/helm-doc/generator"
+	"io"
+	"sort"
+	"strings"
+)
+
+// lintReports maps the names of lint report formats to their writers
+var lintReports = map[string]func(out io.Writer, findings []*generator.Finding) error{
+	"junit":      writeJUnitReport,
+	"sarif":      writeSarifReport,
+	"checkstyle": writeCheckstyleReport,
+}
+
+// LintReportFormats returns the names of all lint report formats
+func LintReportFormats() []string {
+
+	var names []string
+
+	for name := range lintReports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// WriteLintReport writes the findings of lint in a format understood by CI systems
+func WriteLintReport(out io.Writer, findings []*generator.Finding, format string) error {
+
+	writeReport, exists := lintReports[format]
+	if !exists {
+		return fmt.Errorf("unknown report format %q, supported formats: %s", format, strings.Join(LintReportFormats(), ", "))
+	}
+
+	return writeReport(out, findings)
+}
+
+func findingText(finding *generator.Finding) string {
+	if finding.Key == "" {
+		return finding.Message
+	}
+	if finding.Chart == "" {
+		return fmt.Sprintf("%s: %s", finding.Message, finding.Key)
+	}
+	return fmt.Sprintf("%s in %s: %s", finding.Message, finding.Chart, finding.Key)
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnitReport writes a test case per finding, only errors are failures
+func writeJUnitReport(out io.Writer, findings []*generator.Finding) error {
+
+	suite := junitTestSuite{Name: "helm-doc lint"}
+
+	for _, finding := range findings {
+		testCase := junitTestCase{Name: strings.TrimSpace(finding.Chart + " " + finding.Key), ClassName: finding.Rule, File: finding.File}
+		if finding.Severity == generator.SeverityError {
+			testCase.Failure = &junitFailure{Message: findingText(finding), Type: finding.Severity, Text: finding.File}
+			suite.Failures++
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	if len(suite.Cases) == 0 {
+		suite.Cases = append(suite.Cases, junitTestCase{Name: "lint", ClassName: "helm-doc"})
+	}
+	suite.Tests = len(suite.Cases)
+
+	return writeXML(out, junitTestSuites{Suites: []junitTestSuite{suite}})
+}
+
+type checkstyleReport struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// writeCheckstyleReport groups the findings by file, line numbers are unknown
+func writeCheckstyleReport(out io.Writer, findings []*generator.Finding) error {
+
+	report := checkstyleReport{Version: "4.3"}
+	fileIndex := map[string]int{}
+
+	for _, finding := range findings {
+		index, exists := fileIndex[finding.File]
+		if !exists {
+			index = len(report.Files)
+			fileIndex[finding.File] = index
+			report.Files = append(report.Files, checkstyleFile{Name: finding.File})
+		}
+		report.Files[index].Errors = append(report.Files[index].Errors, checkstyleError{
+			Line:     1,
+			Severity: finding.Severity,
+			Message:  findingText(finding),
+			Source:   "helm-doc." + finding.Rule,
+		})
+	}
+
+	return writeXML(out, report)
+}
+
+func writeXML(out io.Writer, report interface{}) error {
+
+	serialized, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to serialize report: %v", err)
+	}
+
+	if _, err := fmt.Fprintf(out, "%s%s\n", xml.Header, serialized); err != nil {
+		return fmt.Errorf("failed to write output: %v", err)
+	}
+
+	return nil
+}
+
+// writeSarifReport writes a SARIF 2.1.0 log, which is understood by code scanning of GitHub and others
+func writeSarifReport(out io.Writer, findings []*generator.Finding) error {
+
+	var rules []string
+	var results []interface{}
+
+	for _, finding := range findings {
+		if !containsRule(rules, finding.Rule) {
+			rules = append(rules, finding.Rule)
+		}
+
+		var locations []interface{}
+		for _, file := range append([]string{finding.File}, finding.Locations...) {
+			if file == "" {
+				continue
+			}
+			locations = append(locations, map[string]interface{}{
+				"physicalLocation": map[string]interface{}{
+					"artifactLocation": map[string]interface{}{"uri": file},
+				},
+			})
+		}
+
+		result := map[string]interface{}{
+			"ruleId":  finding.Rule,
+			"level":   finding.Severity,
+			"message": map[string]interface{}{"text": findingText(finding)},
+		}
+		if len(locations) > 0 {
+			result["locations"] = locations
+		}
+		results = append(results, result)
+	}
+	sort.Strings(rules)
+
+	var ruleDescriptors []interface{}
+	for _, rule := range rules {
+		ruleDescriptors = append(ruleDescriptors, map[string]interface{}{"id": rule})
+	}
+
+	report := map[string]interface{}{
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"version": "2.1.0",
+		"runs": []interface{}{
+			map[string]interface{}{
+				"tool": map[string]interface{}{
+					"driver": map[string]interface{}{
+						"name":           "helm-doc",
+						"informationUri": "https://github.com/random-dwi/helm-doc",
+						"rules":          nonNil(ruleDescriptors),
+					},
+				},
+				"results": nonNil(results),
+			},
+		},
+	}
+
+	serialized, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to serialize report: %v", err)
+	}
+
+	if _, err := fmt.Fprintln(out, string(serialized)); err != nil {
+		return fmt.Errorf("failed to write output: %v", err)
+	}
+
+	return nil
+}
+
+func containsRule(rules []string, rule string) bool {
+	for _, existing := range rules {
+		if existing == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// SARIF requires arrays, even if they are empty
+func nonNil(values []interface{}) []interface{} {
+	if values == nil {
+		return []interface{}{}
+	}
+	return values
+}
@@ -0,0 +1,106 @@
+package writer
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/random-dwi/helm-doc/generator"
+	"testing"
+)
+
+var testFindings = []*generator.Finding{
+	{Chart: "app:1.0.0", Key: "name", Rule: "undocumented-value", Severity: "error", File: "app/values.yaml", Message: "undocumented values detected"},
+	{Chart: "db:1.0.0", Key: "port", Rule: "undocumented-template-value", Severity: "warning", File: "app/charts/db/values.yaml", Message: "values used in templates without documentation or default detected",
+		Locations: []string{"app/charts/db/templates/service.yaml"}},
+}
+
+func Test_WriteLintReport(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		findings []*generator.Finding
+		want     string
+		wantErr  bool
+	}{
+		{name: "junit", format: "junit", findings: testFindings, want: `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="helm-doc lint" tests="2" failures="1">
+    <testcase name="app:1.0.0 name" classname="undocumented-value" file="app/values.yaml">
+      <failure message="undocumented values detected in app:1.0.0: name" type="error">app/values.yaml</failure>
+    </testcase>
+    <testcase name="db:1.0.0 port" classname="undocumented-template-value" file="app/charts/db/values.yaml"></testcase>
+  </testsuite>
+</testsuites>
+`},
+		{name: "junit_without_findings", format: "junit", want: `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="helm-doc lint" tests="1" failures="0">
+    <testcase name="lint" classname="helm-doc"></testcase>
+  </testsuite>
+</testsuites>
+`},
+		{name: "checkstyle", format: "checkstyle", findings: testFindings, want: `<?xml version="1.0" encoding="UTF-8"?>
+<checkstyle version="4.3">
+  <file name="app/values.yaml">
+    <error line="1" severity="error" message="undocumented values detected in app:1.0.0: name" source="helm-doc.undocumented-value"></error>
+  </file>
+  <file name="app/charts/db/values.yaml">
+    <error line="1" severity="warning" message="values used in templates without documentation or default detected in db:1.0.0: port" source="helm-doc.undocumented-template-value"></error>
+  </file>
+</checkstyle>
+`},
+		{name: "unknown", format: "text", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := WriteLintReport(&out, tt.findings, tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("WriteLintReport() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if out.String() != tt.want {
+				t.Errorf("WriteLintReport() = %s, want %s", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func Test_WriteLintReport_sarif(t *testing.T) {
+
+	var out bytes.Buffer
+	if err := WriteLintReport(&out, testFindings, "sarif"); err != nil {
+		t.Fatal(err)
+	}
+
+	var report struct {
+		Version string
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct{ ID string }
+				}
+			}
+			Results []struct {
+				RuleID    string
+				Level     string
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct{ URI string }
+					}
+				}
+			}
+		}
+	}
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+
+	run := report.Runs[0]
+	if report.Version != "2.1.0" || len(run.Tool.Driver.Rules) != 2 || len(run.Results) != 2 {
+		t.Fatalf("WriteLintReport() = %s, want a run with 2 rules and 2 results", out.String())
+	}
+	if result := run.Results[1]; result.Level != "warning" || len(result.Locations) != 2 ||
+		result.Locations[1].PhysicalLocation.ArtifactLocation.URI != "app/charts/db/templates/service.yaml" {
+		t.Errorf("WriteLintReport() result = %+v, want warning with file and template locations", result)
+	}
+}
@@ -3,10 +3,9 @@ package writer
 import (
 	"fmt"
 	"github.com/random-dwi/helm-doc/generator"
-	"github.com/random-dwi/helm-doc/output"
 	"gopkg.in/yaml.v2"
+	"helm.sh/helm/v3/pkg/chart"
 	"io"
-	"k8s.io/helm/pkg/proto/hapi/chart"
 	"regexp"
 	"sort"
 	"strings"
@@ -14,72 +13,251 @@ import (
 
 type MarkdownWriter struct {
 	writer io.Writer
+	// page, prefix and links are set for docs split into several pages
+	page   string
+	prefix string
+	// links maps the keys of all charts, prefixed with the keys of their charts, to the pages documenting them
+	links map[string]string
 }
 
 func NewMarkdownWriter(writer io.Writer) MarkdownWriter {
 	return MarkdownWriter{writer: writer}
 }
 
-func (g MarkdownWriter) WriteChapter(title string, layer int) {
+// NewLinkedMarkdownWriter creates a writer for one page of docs split into several pages.
+// Keys get anchors and keys of other pages mentioned in descriptions are linked to them.
+// prefix is the key of the values of the chart of the page, e.g. `db` for a dependency.
+func NewLinkedMarkdownWriter(writer io.Writer, page string, prefix string, links map[string]string) MarkdownWriter {
+	return MarkdownWriter{writer: writer, page: page, prefix: prefix, links: links}
+}
+
+// WriteChartTable writes a chapter listing charts with their versions and descriptions, linked to their docs
+func (g MarkdownWriter) WriteChartTable(title string, charts []*chart.Metadata, links []string) error {
 
-	for i := 0; i < layer; i++ {
-		g.fprintf("#")
+	if err := g.WriteChapter(title, 1); err != nil {
+		return err
+	}
+	if err := g.fprintf("|CHART|VERSION|APP VERSION|DESCRIPTION|\n|---|---|---|---|\n"); err != nil {
+		return err
+	}
+	for i, metaData := range charts {
+		if err := g.fprintf("|[%s](%s)|%s|%s|%s|\n", metaData.Name, links[i], metaData.Version, sanitize(metaData.AppVersion), sanitize(metaData.Description)); err != nil {
+			return err
+		}
 	}
+	return g.fprintf("\n")
+}
+
+// WriteIndexEntry writes a link to the page of a chart, indented by the depth of the chart in the tree
+func (g MarkdownWriter) WriteIndexEntry(title string, page string, metaData *chart.Metadata, depth int) error {
+	return g.fprintf("%s- [%s](%s) %s: %s\n", strings.Repeat("  ", depth), title, page, metaData.Version, metaData.Description)
+}
 
-	g.fprintf(" %s\n\n", title)
+func (g MarkdownWriter) WriteChapter(title string, layer int) error {
+	return g.fprintf("%s %s\n\n", strings.Repeat("#", layer), title)
 }
 
-func (g MarkdownWriter) WriteMetaData(metaData *chart.Metadata, layer int) {
+func (g MarkdownWriter) WriteMetaData(metaData *chart.Metadata, declaration *chart.Dependency, layer int, depth int) error {
 
-	for i := 0; i < layer; i++ {
-		g.fprintf("#")
+	// aliases of the same chart are told apart by their alias
+	title := generator.ValuesKey(metaData, declaration)
+	var dependencyInfo string
+
+	if declaration != nil {
+		if declaration.Alias != "" {
+			dependencyInfo += fmt.Sprintf("- **Chart:** %s\n", metaData.Name)
+		}
+		if declaration.Condition != "" {
+			dependencyInfo += fmt.Sprintf("- **Condition:** `%s`\n", declaration.Condition)
+		}
+		if len(declaration.Tags) > 0 {
+			dependencyInfo += fmt.Sprintf("- **Tags:** `%s`\n", strings.Join(declaration.Tags, "`, `"))
+		}
 	}
 
-	g.fprintf(" %s\n\n", metaData.Name)
-	g.fprintf("- **Version:** %s\n", metaData.Version)
-	g.fprintf("- **Description:** %s\n", metaData.Description)
-	g.fprintf("\n")
+	return g.fprintf("%s %s\n\n- **Version:** %s\n- **Description:** %s\n%s\n",
+		strings.Repeat("#", layer), title, metaData.Version, metaData.Description, dependencyInfo)
 }
 
-func (g MarkdownWriter) WriteDocs(docs map[string]*generator.ConfigDoc) {
+func (g MarkdownWriter) WriteDocs(docs map[string]*generator.ConfigDoc) error {
 
 	if len(docs) == 0 {
-		return
+		return nil
 	}
 
-	g.fprintf("|%s|%s|%s|%s|\n", "KEY", "DESCRIPTION", "DEFAULT", "EXAMPLE")
-	g.fprintf("|---|---|---|---|\n")
-
 	var keysSorted []string
+	var hasTypes = false
+	var hasUsage = false
+	var hasCharts = false
 
-	for key := range docs {
+	for key, configDoc := range docs {
 		keysSorted = append(keysSorted, key)
+		hasTypes = hasTypes || hasTypeMetadata(configDoc)
+		hasUsage = hasUsage || len(configDoc.Templates) > 0
+		hasCharts = hasCharts || len(configDoc.Charts) > 0
 	}
 	sort.Strings(keysSorted)
 
+	header := []string{"KEY", "DESCRIPTION", "DEFAULT", "EXAMPLE"}
+	if hasTypes {
+		header = []string{"KEY", "TYPE", "DESCRIPTION", "DEFAULT", "EXAMPLE"}
+	}
+	if hasUsage {
+		header = append(header, "USED IN")
+	}
+	if hasCharts {
+		header = append(header, "READ BY")
+	}
+
+	if err := g.fprintf("|%s|\n|%s|\n", strings.Join(header, "|"), strings.Repeat("---|", len(header)-1)+"---"); err != nil {
+		return err
+	}
+
 	for _, key := range keysSorted {
 		var configDoc = docs[key]
-		row := []string{"`" + key + "`", sanitize(configDoc.Description), toMarkdown(configDoc.DefaultValue), toMarkdown(configDoc.ExampleValue)}
-		g.fprintf("|%s|\n", strings.Join(row, "|"))
+		row := []string{"`" + key + "`"}
+		if configDoc.Deprecated != "" {
+			row[0] = "~~" + row[0] + "~~"
+		}
+		if g.links != nil {
+			row[0] = fmt.Sprintf(`<a id="%s"></a>%s`, generator.PrefixKey(g.prefix, key), row[0])
+		}
+		if hasTypes {
+			row = append(row, typeToMarkdown(configDoc))
+		}
+		defaultValue, err := toMarkdown(configDoc.DefaultValue)
+		if err != nil {
+			return err
+		}
+		exampleValue, err := toMarkdown(configDoc.ExampleValue)
+		if err != nil {
+			return err
+		}
+		row = append(row, g.linkKeys(descriptionToMarkdown(configDoc)), defaultValue, exampleValue)
+		if hasUsage {
+			row = append(row, usageToMarkdown(configDoc))
+		}
+		if hasCharts {
+			row = append(row, sanitize(strings.Join(configDoc.Charts, ", ")))
+		}
+		if err := g.fprintf("|%s|\n", strings.Join(row, "|")); err != nil {
+			return err
+		}
+	}
+	return g.fprintf("\n")
+}
+
+func (g MarkdownWriter) WriteGlobals(docs map[string]*generator.ConfigDoc, layer int) error {
+
+	if len(docs) == 0 {
+		return nil
+	}
+
+	if err := g.WriteChapter(globalsTitle, layer); err != nil {
+		return err
+	}
+	return g.WriteDocs(docs)
+}
+
+func (g MarkdownWriter) Flush() error {
+	return nil
+}
+
+var mentionedKeyRegex = regexp.MustCompile("`?[\\w\\-\\[\\]]+(\\.[\\w\\-\\[\\]]+)+`?")
+
+// linkKeys links keys of other pages mentioned in a description, e.g. `db.port` or db.port.
+// Mentions are keys of the chart of the page first, e.g. `service.port` on the page of db is `db.service.port`.
+func (g MarkdownWriter) linkKeys(description string) string {
+
+	if g.links == nil {
+		return description
 	}
-	g.fprintf("\n")
+
+	return mentionedKeyRegex.ReplaceAllStringFunc(description, func(mention string) string {
+		key := generator.PrefixKey(g.prefix, strings.Trim(mention, "`"))
+		page, exists := g.links[key]
+		if !exists {
+			key = strings.Trim(mention, "`")
+			page, exists = g.links[key]
+		}
+		if !exists || page == g.page {
+			return mention
+		}
+		return fmt.Sprintf("[%s](%s#%s)", mention, page, key)
+	})
 }
 
-func toMarkdown(object interface{}) string {
+func hasTypeMetadata(configDoc *generator.ConfigDoc) bool {
+	return configDoc.Type != "" || configDoc.Required || len(configDoc.Enum) > 0 || configDoc.Pattern != ""
+}
+
+func typeToMarkdown(configDoc *generator.ConfigDoc) string {
+
+	var parts []string
+
+	if configDoc.Type != "" {
+		parts = append(parts, configDoc.Type)
+	}
+	if configDoc.Required {
+		parts = append(parts, "**required**")
+	}
+	if len(configDoc.Enum) > 0 {
+		var values []string
+		for _, value := range configDoc.Enum {
+			values = append(values, fmt.Sprintf("<code>%v</code>", value))
+		}
+		parts = append(parts, "one of: "+strings.Join(values, ", "))
+	}
+	if configDoc.Pattern != "" {
+		parts = append(parts, fmt.Sprintf("pattern: <code>%s</code>", strings.Replace(configDoc.Pattern, "|", "&#124;", -1)))
+	}
+
+	return sanitize(strings.Join(parts, "\n"))
+}
+
+func descriptionToMarkdown(configDoc *generator.ConfigDoc) string {
+
+	if configDoc.Deprecated == "" {
+		return sanitize(configDoc.Description)
+	}
+
+	deprecation := "**deprecated:** " + configDoc.Deprecated
+	if configDoc.ReplacedBy != "" {
+		deprecation += fmt.Sprintf(" (replaced by `%s`)", configDoc.ReplacedBy)
+	}
+
+	return sanitize(strings.TrimSpace(configDoc.Description + "\n" + deprecation))
+}
+
+func usageToMarkdown(configDoc *generator.ConfigDoc) string {
+
+	var parts []string
+
+	if len(configDoc.Kinds) > 0 {
+		parts = append(parts, strings.Join(configDoc.Kinds, ", "))
+	}
+	for _, template := range configDoc.Templates {
+		parts = append(parts, fmt.Sprintf("<code>%s</code>", template))
+	}
+
+	return sanitize(strings.Join(parts, "\n"))
+}
+
+func toMarkdown(object interface{}) (string, error) {
 	if object == nil {
 		//to avoid removal of table cell
-		return " "
+		return " ", nil
 	}
 
 	mapObject, isMap := object.(map[string]interface{})
 	if isMap {
 		serialized, err := yaml.Marshal(mapObject)
 		if err != nil {
-			output.Failf("unable to serialize object: %v", mapObject)
+			return "", fmt.Errorf("unable to serialize object: %v", mapObject)
 		}
-		return sanitize(fmt.Sprintf("<code>%v</code>", string(serialized)))
+		return sanitize(fmt.Sprintf("<code>%v</code>", string(serialized))), nil
 	} else {
-		return sanitize(fmt.Sprintf("<code>%v</code>", object))
+		return sanitize(fmt.Sprintf("<code>%v</code>", object)), nil
 	}
 }
 
@@ -96,13 +274,15 @@ func sanitize(value string) string {
 	}
 }
 
-func (g MarkdownWriter) fprintf(format string, a ...interface{}) {
+func (g MarkdownWriter) fprintf(format string, a ...interface{}) error {
 
 	var err error
 
 	_, err = fmt.Fprintf(g.writer, format, a...)
 
 	if err != nil {
-		output.Failf("Failed to write output: %v", err)
+		return fmt.Errorf("failed to write output: %v", err)
 	}
+
+	return nil
 }
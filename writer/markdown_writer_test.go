@@ -0,0 +1,38 @@
+package writer
+
+import (
+	"testing"
+)
+
+func Test_linkKeys(t *testing.T) {
+	links := map[string]string{"db.port": "app.db.md", "replicas": "app.md", "global.region": "index.md", "image.tag": "app.md",
+		"service.port": "app.md", "db.service.port": "app.db.md", "db.user.name": "app.db.md"}
+	tests := []struct {
+		name        string
+		page        string
+		prefix      string
+		description string
+		want        string
+	}{
+		{name: "plain", description: "see&nbsp;db.port.", want: "see&nbsp;[db.port](app.db.md#db.port)."},
+		{name: "code", description: "see&nbsp;`db.port`", want: "see&nbsp;[`db.port`](app.db.md#db.port)"},
+		{name: "global", description: "defaults&nbsp;to&nbsp;global.region", want: "defaults&nbsp;to&nbsp;[global.region](index.md#global.region)"},
+		{name: "same_page", description: "overrides&nbsp;image.tag", want: "overrides&nbsp;image.tag"},
+		{name: "unknown", description: "see&nbsp;www.example.com", want: "see&nbsp;www.example.com"},
+		{name: "dependency_key", page: "app.md", description: "see&nbsp;db.user.name", want: "see&nbsp;[db.user.name](app.db.md#db.user.name)"},
+		{name: "key_of_own_chart", page: "app.db.md", prefix: "db", description: "defaults&nbsp;to&nbsp;service.port", want: "defaults&nbsp;to&nbsp;service.port"},
+		{name: "root_key_from_dependency", page: "app.db.md", prefix: "db", description: "see&nbsp;image.tag", want: "see&nbsp;[image.tag](app.md#image.tag)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := tt.page
+			if page == "" {
+				page = "app.md"
+			}
+			g := NewLinkedMarkdownWriter(nil, page, tt.prefix, links)
+			if got := g.linkKeys(tt.description); got != tt.want {
+				t.Errorf("linkKeys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package writer
+
+import (
+	"github.com/random-dwi/helm-doc/generator"
+	"github.com/random-dwi/helm-doc/output"
+	"helm.sh/helm/v3/pkg/chart"
+	"io"
+)
+
+// ChartDoc is the structured doc of a chart and its dependencies written by the ObjectWriter
+type ChartDoc struct {
+	Name         string                          `json:"name" yaml:"name"`
+	Version      string                          `json:"version" yaml:"version"`
+	Description  string                          `json:"description,omitempty" yaml:"description,omitempty"`
+	Alias        string                          `json:"alias,omitempty" yaml:"alias,omitempty"`
+	Condition    string                          `json:"condition,omitempty" yaml:"condition,omitempty"`
+	Tags         []string                        `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Values       map[string]*generator.ConfigDoc `json:"values" yaml:"values"`
+	Dependencies []*ChartDoc                     `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
+	// Globals are the global values of all charts, only set for the root chart
+	Globals map[string]*generator.ConfigDoc `json:"globals,omitempty" yaml:"globals,omitempty"`
+}
+
+// ObjectWriter writes the docs as a json or yaml document for consumption by other tools.
+// The document is buffered until Flush is called, as dependencies are nested into their parents.
+type ObjectWriter struct {
+	writer io.Writer
+	format string
+	root   *ChartDoc
+	// the current chart and its parents
+	charts []*ChartDoc
+}
+
+func NewObjectWriter(writer io.Writer, format string) *ObjectWriter {
+	return &ObjectWriter{writer: writer, format: format}
+}
+
+func (g *ObjectWriter) WriteChapter(title string, layer int) error {
+	return nil
+}
+
+func (g *ObjectWriter) WriteMetaData(metaData *chart.Metadata, declaration *chart.Dependency, layer int, depth int) error {
+
+	chartDoc := &ChartDoc{
+		Name:        metaData.Name,
+		Version:     metaData.Version,
+		Description: metaData.Description,
+		Values:      map[string]*generator.ConfigDoc{},
+	}
+	if declaration != nil {
+		chartDoc.Alias = declaration.Alias
+		chartDoc.Condition = declaration.Condition
+		chartDoc.Tags = declaration.Tags
+	}
+
+	if depth < len(g.charts) {
+		g.charts = g.charts[:depth]
+	}
+
+	if depth == 0 {
+		g.root = chartDoc
+	} else {
+		parent := g.charts[depth-1]
+		parent.Dependencies = append(parent.Dependencies, chartDoc)
+	}
+	g.charts = append(g.charts, chartDoc)
+
+	return nil
+}
+
+func (g *ObjectWriter) WriteDocs(docs map[string]*generator.ConfigDoc) error {
+	if len(g.charts) > 0 && docs != nil {
+		g.charts[len(g.charts)-1].Values = docs
+	}
+	return nil
+}
+
+func (g *ObjectWriter) WriteGlobals(docs map[string]*generator.ConfigDoc, layer int) error {
+	if g.root != nil && len(docs) > 0 {
+		g.root.Globals = docs
+	}
+	return nil
+}
+
+func (g *ObjectWriter) Flush() error {
+	return output.FprintObject(g.writer, g.root, g.format)
+}
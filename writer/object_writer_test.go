@@ -0,0 +1,63 @@
+package writer
+
+import (
+	"bytes"
+	"github.com/random-dwi/helm-doc/generator"
+	"helm.sh/helm/v3/pkg/chart"
+	"testing"
+)
+
+func Test_ObjectWriter(t *testing.T) {
+
+	var out bytes.Buffer
+	gen := NewObjectWriter(&out, "yaml")
+
+	charts := []struct {
+		name  string
+		layer int
+		depth int
+	}{{"app", 1, 0}, {"db", 3, 1}, {"metrics", 5, 2}, {"cache", 3, 1}}
+
+	for _, c := range charts {
+		if err := gen.WriteMetaData(&chart.Metadata{Name: c.name, Version: "1.0.0"}, nil, c.layer, c.depth); err != nil {
+			t.Fatal(err)
+		}
+		if err := gen.WriteDocs(map[string]*generator.ConfigDoc{"enabled": {Description: c.name, DefaultValue: true}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := gen.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `name: app
+version: 1.0.0
+values:
+  enabled:
+    description: app
+    default: true
+dependencies:
+- name: db
+  version: 1.0.0
+  values:
+    enabled:
+      description: db
+      default: true
+  dependencies:
+  - name: metrics
+    version: 1.0.0
+    values:
+      enabled:
+        description: metrics
+        default: true
+- name: cache
+  version: 1.0.0
+  values:
+    enabled:
+      description: cache
+      default: true
+`
+	if out.String() != want {
+		t.Errorf("ObjectWriter wrote:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
@@ -0,0 +1,219 @@
+package writer
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/random-dwi/helm-doc/generator"
+	"helm.sh/helm/v3/pkg/chart"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+const schemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// SchemaWriter writes a values.schema.json for the top level chart.
+// Dependencies are skipped, since helm validates every subchart against its own schema.
+type SchemaWriter struct {
+	writer io.Writer
+	layer  int
+}
+
+func NewSchemaWriter(writer io.Writer) *SchemaWriter {
+	return &SchemaWriter{writer: writer}
+}
+
+func (g *SchemaWriter) WriteChapter(title string, layer int) error {
+	g.layer = layer
+	return nil
+}
+
+func (g *SchemaWriter) WriteMetaData(metaData *chart.Metadata, declaration *chart.Dependency, layer int, depth int) error {
+	g.layer = layer
+	return nil
+}
+
+func (g *SchemaWriter) WriteDocs(docs map[string]*generator.ConfigDoc) error {
+
+	if g.layer != 1 {
+		return nil
+	}
+
+	schema := buildSchema(docs)
+
+	serialized, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to serialize schema: %v", err)
+	}
+
+	_, err = fmt.Fprintln(g.writer, string(serialized))
+	if err != nil {
+		return fmt.Errorf("failed to write output: %v", err)
+	}
+
+	return nil
+}
+
+// WriteGlobals writes nothing, the global values of the chart are part of its schema already
+func (g *SchemaWriter) WriteGlobals(docs map[string]*generator.ConfigDoc, layer int) error {
+	return nil
+}
+
+func (g *SchemaWriter) Flush() error {
+	return nil
+}
+
+func buildSchema(docs map[string]*generator.ConfigDoc) map[string]interface{} {
+
+	root := newObjectSchema()
+	root["$schema"] = schemaDraft
+
+	var keysSorted []string
+
+	for key := range docs {
+		keysSorted = append(keysSorted, key)
+	}
+	sort.Strings(keysSorted)
+
+	for _, key := range keysSorted {
+		insertIntoSchema(root, strings.Split(key, "."), docs[key])
+	}
+
+	return root
+}
+
+// insertIntoSchema walks down the given key path, creating nested objects and array items on the way
+func insertIntoSchema(schema map[string]interface{}, keys []string, configDoc *generator.ConfigDoc) {
+
+	baseKey, isArray := isArrayKey(keys[0])
+
+	properties, hasProperties := schema["properties"].(map[string]interface{})
+	if !hasProperties {
+		properties = map[string]interface{}{}
+		schema["properties"] = properties
+	}
+
+	property, exists := properties[baseKey].(map[string]interface{})
+	if !exists {
+		property = map[string]interface{}{}
+		properties[baseKey] = property
+	}
+
+	if isArray {
+		property["type"] = "array"
+		items, hasItems := property["items"].(map[string]interface{})
+		if !hasItems {
+			items = newObjectSchema()
+			property["items"] = items
+		}
+		if len(keys) == 1 {
+			// documentation for the array elements themselves
+			addLeafSchema(items, configDoc)
+		} else {
+			insertIntoSchema(items, keys[1:], configDoc)
+		}
+		return
+	}
+
+	if len(keys) == 1 {
+		addLeafSchema(property, configDoc)
+		if configDoc.Required {
+			addRequired(schema, baseKey)
+		}
+		return
+	}
+
+	if _, hasType := property["type"]; !hasType {
+		property["type"] = "object"
+	}
+	insertIntoSchema(property, keys[1:], configDoc)
+}
+
+func addLeafSchema(schema map[string]interface{}, configDoc *generator.ConfigDoc) {
+
+	if configDoc.Description != "" {
+		schema["description"] = configDoc.Description
+	}
+
+	if configDoc.DefaultValue != nil {
+		schema["default"] = configDoc.DefaultValue
+	}
+
+	if configDoc.Deprecated != "" {
+		schema["deprecated"] = true
+	}
+
+	nullable := configDoc.DefaultValue == nil && !configDoc.Required
+
+	if len(configDoc.Enum) > 0 {
+		enum := append([]interface{}{}, configDoc.Enum...)
+		if nullable {
+			enum = append(enum, nil)
+		}
+		schema["enum"] = enum
+	}
+
+	if configDoc.Pattern != "" {
+		schema["pattern"] = configDoc.Pattern
+	}
+
+	schemaType := configDoc.Type
+	if schemaType == "" {
+		schemaType = inferSchemaType(configDoc.DefaultValue)
+	}
+	if schemaType == "" {
+		schemaType = inferSchemaType(configDoc.ExampleValue)
+	}
+
+	if schemaType == "" {
+		return
+	}
+
+	if nullable {
+		// helm validates the merged values, so an optional key without default has to accept null
+		schema["type"] = []string{schemaType, "null"}
+	} else {
+		schema["type"] = schemaType
+	}
+}
+
+func addRequired(schema map[string]interface{}, key string) {
+	required, _ := schema["required"].([]string)
+	schema["required"] = append(required, key)
+}
+
+func inferSchemaType(value interface{}) string {
+	switch typed := value.(type) {
+	case nil:
+		return ""
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case int, int32, int64:
+		return "integer"
+	case float64:
+		if typed == math.Trunc(typed) {
+			return "integer"
+		}
+		return "number"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	default:
+		return ""
+	}
+}
+
+func newObjectSchema() map[string]interface{} {
+	return map[string]interface{}{"type": "object"}
+}
+
+func isArrayKey(key string) (string, bool) {
+	if strings.HasSuffix(key, "[]") {
+		return strings.TrimSuffix(key, "[]"), true
+	}
+	return key, false
+}
@@ -0,0 +1,83 @@
+package writer
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/random-dwi/helm-doc/generator"
+	"github.com/random-dwi/helm-doc/output"
+)
+
+func Test_buildSchema(t *testing.T) {
+	tests := []struct {
+		name string
+		docs map[string]*generator.ConfigDoc
+		want string
+	}{
+		{name: "no_docs", docs: map[string]*generator.ConfigDoc{},
+			want: `{"$schema": "http://json-schema.org/draft-07/schema#", "type": "object"}`},
+		{name: "default_types", docs: map[string]*generator.ConfigDoc{
+			"int":    {Description: "an int", DefaultValue: 1.0},
+			"float":  {DefaultValue: 1.5},
+			"bool":   {DefaultValue: true},
+			"string": {DefaultValue: "value"},
+		},
+			want: `{"$schema": "http://json-schema.org/draft-07/schema#", "type": "object", "properties": {
+				"int": {"type": "integer", "description": "an int", "default": 1},
+				"float": {"type": "number", "default": 1.5},
+				"bool": {"type": "boolean", "default": true},
+				"string": {"type": "string", "default": "value"}}}`},
+		{name: "example_type_is_nullable", docs: map[string]*generator.ConfigDoc{
+			"password": {Description: "secret", ExampleValue: "changeme"},
+		},
+			want: `{"$schema": "http://json-schema.org/draft-07/schema#", "type": "object", "properties": {
+				"password": {"type": ["string", "null"], "description": "secret"}}}`},
+		{name: "nested", docs: map[string]*generator.ConfigDoc{
+			"service.port": {DefaultValue: 80.0},
+		},
+			want: `{"$schema": "http://json-schema.org/draft-07/schema#", "type": "object", "properties": {
+				"service": {"type": "object", "properties": {"port": {"type": "integer", "default": 80}}}}}`},
+		{name: "array", docs: map[string]*generator.ConfigDoc{
+			"hosts[].name": {ExampleValue: "example.com"},
+		},
+			want: `{"$schema": "http://json-schema.org/draft-07/schema#", "type": "object", "properties": {
+				"hosts": {"type": "array", "items": {"type": "object", "properties": {"name": {"type": ["string", "null"]}}}}}}`},
+		{name: "typed", docs: map[string]*generator.ConfigDoc{
+			"service.type":  {Type: "string", Enum: []interface{}{"ClusterIP", "NodePort"}, DefaultValue: "ClusterIP"},
+			"service.name":  {Type: "string", Pattern: "^[a-z]+$"},
+			"service.token": {Type: "string", Required: true},
+		},
+			want: `{"$schema": "http://json-schema.org/draft-07/schema#", "type": "object", "properties": {
+				"service": {"type": "object", "required": ["token"], "properties": {
+					"type": {"type": "string", "enum": ["ClusterIP", "NodePort"], "default": "ClusterIP"},
+					"name": {"type": ["string", "null"], "pattern": "^[a-z]+$"},
+					"token": {"type": "string"}}}}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := roundTrip(buildSchema(tt.docs))
+			if want := parseJson(tt.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("buildSchema() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func roundTrip(object interface{}) map[string]interface{} {
+	serialized, err := json.Marshal(object)
+	if err != nil {
+		output.Failf("error serializing json: %v", err)
+	}
+	return parseJson(string(serialized))
+}
+
+func parseJson(value string) map[string]interface{} {
+	valueMap := map[string]interface{}{}
+
+	if err := json.Unmarshal([]byte(value), &valueMap); err != nil {
+		output.Failf("error parsing json: %v", err)
+	}
+
+	return valueMap
+}